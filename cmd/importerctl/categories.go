@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/query"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+var categoriesCommand = cli.Command{
+	Name:      "categories",
+	Usage:     "Print category tree, and optionally its pages",
+	ArgsUsage: "<category>",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "depth",
+			Value: 3,
+			Usage: "Maximum subcategory depth",
+		},
+		cli.BoolFlag{
+			Name:  "pages",
+			Usage: "List pages of category and its subcategories instead of tree",
+		},
+	},
+	Action: categories,
+}
+
+func categories(c *cli.Context) error {
+	category := title.Normalize(strings.Join(c.Args(), " "), title.FirstLetter)
+	if category == "" {
+		return fmt.Errorf("missing category")
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("pages") {
+		members, err := query.CategoryMembers(db, c.GlobalString("language"), category, c.Int("depth"))
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			fmt.Printf("%d\t%s\t%s\n", m.PageID, m.Title, m.Category)
+		}
+		return nil
+	}
+
+	tree, err := query.CategoryTree(db, c.GlobalString("language"), category, c.Int("depth"))
+	if err != nil {
+		return err
+	}
+
+	children := make(map[string][]string)
+	for _, t := range tree {
+		parent := title.Key(t.Parent)
+		children[parent] = append(children[parent], t.Title)
+	}
+
+	fmt.Println(category)
+	printCategoryTree(children, title.Key(category), 1, c.Int("depth"), map[string]bool{title.Key(category): true})
+	return nil
+}
+
+// printCategoryTree prints subcategories of parent indented by depth, each category once
+func printCategoryTree(children map[string][]string, parent string, depth int, maxDepth int, printed map[string]bool) {
+	if depth > maxDepth {
+		return
+	}
+
+	for _, category := range children[parent] {
+		key := title.Key(category)
+		if printed[key] {
+			continue
+		}
+		printed[key] = true
+
+		fmt.Printf("%s%s\n", strings.Repeat("  ", depth), category)
+		printCategoryTree(children, key, depth+1, maxDepth, printed)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/classifier"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/importer"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+var classifyCommand = cli.Command{
+	Name:  "classify",
+	Usage: "Classify a dump sample with a rules file and print confusion summary against baseline rules",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:   "baseline",
+			Usage:  "Rules file giving expected natures, embedded rules are used if not set",
+			EnvVar: "CLASSIFY_BASELINE",
+		},
+		cli.IntFlag{
+			Name:   "sample",
+			Value:  10000,
+			Usage:  "Number of articles classified",
+			EnvVar: "CLASSIFY_SAMPLE",
+		},
+	},
+	Action: classify,
+}
+
+// errSampleDone stops dump walk once sample is classified
+var errSampleDone = errors.New("sample done")
+
+func classify(c *cli.Context) error {
+	language := c.GlobalString("language")
+
+	if c.GlobalString("nature-rules") == "" {
+		return fmt.Errorf("missing --nature-rules")
+	}
+	rules, err := natureClassifier(c)
+	if err != nil {
+		return err
+	}
+
+	baseline, err := classifier.Default(language)
+	if f := c.String("baseline"); f != "" {
+		baseline, err = classifier.Load(f, language)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = setLogOutput(c)
+	if err != nil {
+		return err
+	}
+
+	sample := c.Int("sample")
+	confusion := classifier.NewConfusion()
+
+	// dumps are kept whatever --tight says, since only a sample is read
+	err = importer.Walk(c.GlobalString("dump-folder"), false, c.GlobalBool("interactive"), language, func(dumpName string, si *reader.SiteInfo, pagech chan reader.Page) error {
+		fmt.Printf("Classifying %d pages of %s\n", sample, dumpName)
+		ns := parser.NewNamespaces(si)
+		for p := range pagech {
+			if parser.IsMeta(&p) {
+				continue
+			}
+
+			nodes := parser.Parse(p.Text)
+			expected, _ := baseline.Classify(&p, nodes, ns)
+			actual, rule := rules.Classify(&p, nodes, ns)
+			confusion.Add(expected, actual, rule)
+
+			if confusion.Total() >= sample {
+				// reader goroutine is left blocked, process exits right after
+				return errSampleDone
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errSampleDone {
+		return err
+	}
+
+	return confusion.Print(os.Stdout)
+}
+
+// natureClassifier returns classifier using --nature-rules file, or embedded rules if not set
+func natureClassifier(c *cli.Context) (*classifier.Classifier, error) {
+	if f := c.GlobalString("nature-rules"); f != "" {
+		return classifier.Load(f, c.GlobalString("language"))
+	}
+	return classifier.Default(c.GlobalString("language"))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/query"
+)
+
+var collisionsCommand = cli.Command{
+	Name:   "collisions",
+	Usage:  "List distinct pages whose titles only differ by case, such as MIT and Mit",
+	Action: collisions,
+}
+
+func collisions(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	collisions, err := query.TitleCollisions(db, c.GlobalString("language"))
+	if err != nil {
+		return err
+	}
+
+	for _, collision := range collisions {
+		fmt.Printf("%s\t%s\n", collision.Key, strings.Join(collision.Titles, "\t"))
+	}
+	fmt.Printf("%d collisions\n", len(collisions))
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/dictionary"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/importer"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+var dictionaryCommand = cli.Command{
+	Name:  "dictionary",
+	Usage: "Build anchor text dictionary from dumps, as TSV: phrase, title, count, commonness, link probability",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "output",
+			Value: "./dictionary.tsv",
+			Usage: "Output file",
+		},
+		cli.IntFlag{
+			Name:  "min-count",
+			Value: 1,
+			Usage: "Minimum number of links of a phrase to a page",
+		},
+		cli.IntFlag{
+			Name:  "max-words",
+			Value: 4,
+			Usage: "Maximum number of words of phrases counted in article text",
+		},
+		cli.IntFlag{
+			Name:  "max-keys",
+			Value: 1000000,
+			Usage: "Number of counts held in memory before spilling them to disk",
+		},
+		cli.StringFlag{
+			Name:  "tmp-folder",
+			Value: os.TempDir(),
+			Usage: "Folder receiving spilled counts",
+		},
+	},
+	Action: buildDictionary,
+}
+
+func buildDictionary(c *cli.Context) error {
+	err := setLogOutput(c)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(c.String("output"))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	b := dictionary.New(c.String("tmp-folder"), c.Int("max-words"), c.Int("max-keys"))
+	defer b.Close()
+
+	err = importer.Walk(c.GlobalString("dump-folder"), c.GlobalBool("tight"), c.GlobalBool("interactive"), c.GlobalString("language"), func(dumpName string, si *reader.SiteInfo, pagech chan reader.Page) error {
+		fmt.Printf("Reading dictionary from %s\n", dumpName)
+		ns := parser.NewNamespaces(si)
+		for p := range pagech {
+			if parser.IsMeta(&p) {
+				continue
+			}
+			err := b.AddPage(&p, parser.Parse(p.Text), ns)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	err = b.WriteTSV(w, c.Int("min-count"))
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
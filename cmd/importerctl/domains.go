@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/query"
+)
+
+var domainsCommand = cli.Command{
+	Name:  "domains",
+	Usage: "Print most linked external domains",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "limit",
+			Value: 20,
+			Usage: "Number of domains printed per wiki",
+		},
+		cli.BoolFlag{
+			Name:  "cited",
+			Usage: "Only count links found in citations",
+		},
+		cli.BoolFlag{
+			Name:  "all-wikis",
+			Usage: "Print domains of every imported wiki instead of --language",
+		},
+	},
+	Action: domains,
+}
+
+func domains(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	wikis := []string{c.GlobalString("language")}
+	if c.Bool("all-wikis") {
+		wikis, err = query.LinkedWikis(db)
+		if err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "wiki\tdomain\tlinks\tpages")
+	for _, wiki := range wikis {
+		top, err := query.TopDomains(db, wiki, c.Bool("cited"), c.Int("limit"))
+		if err != nil {
+			return err
+		}
+		for _, d := range top {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", d.Wiki, d.Domain, d.Links, d.Pages)
+		}
+	}
+
+	return tw.Flush()
+}
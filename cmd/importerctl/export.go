@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/exporter"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/importer"
+)
+
+var exportCommand = cli.Command{
+	Name:  "export",
+	Usage: "Export pages and references from dumps to sharded files, without database",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:   "format",
+			Value:  "jsonl",
+			Usage:  "Output format ('jsonl', 'parquet' or 'csv')",
+			EnvVar: "EXPORT_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   "output-folder",
+			Value:  "./export",
+			Usage:  "Folder receiving shards and manifest.json",
+			EnvVar: "EXPORT_FOLDER",
+		},
+		cli.IntFlag{
+			Name:   "shard-size",
+			Value:  100000,
+			Usage:  "Maximum number of pages per shard",
+			EnvVar: "EXPORT_SHARD_SIZE",
+		},
+	},
+	Action: export,
+}
+
+func export(c *cli.Context) error {
+	e, err := exporter.New(c.String("output-folder"), c.String("format"), c.Int("shard-size"), c.GlobalString("language"))
+	if err != nil {
+		return err
+	}
+
+	err = setLogOutput(c)
+	if err != nil {
+		return err
+	}
+
+	return importer.Export(e, c.GlobalString("dump-folder"), c.GlobalBool("tight"), c.GlobalBool("interactive"), c.GlobalString("language"))
+}
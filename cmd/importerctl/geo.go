@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/geo"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/query"
+)
+
+var geoCommand = cli.Command{
+	Name:  "geo",
+	Usage: "Print pages around a point or inside a bounding box",
+	Flags: []cli.Flag{
+		cli.Float64Flag{
+			Name:  "lat",
+			Usage: "Latitude of point, in decimal degrees",
+		},
+		cli.Float64Flag{
+			Name:  "lon",
+			Usage: "Longitude of point, in decimal degrees",
+		},
+		cli.Float64Flag{
+			Name:  "radius",
+			Value: 10,
+			Usage: "Search radius around point, in kilometers",
+		},
+		cli.StringFlag{
+			Name:  "bbox",
+			Usage: "Bounding box as min_lat,min_lon,max_lat,max_lon, used instead of point",
+		},
+	},
+	Action: geoSearch,
+}
+
+func geoSearch(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	if c.String("bbox") != "" {
+		b, err := parseBox(c.String("bbox"))
+		if err != nil {
+			return err
+		}
+		places, err := query.WithinBox(db, c.GlobalString("language"), b)
+		if err != nil {
+			return err
+		}
+		for _, p := range places {
+			fmt.Printf("%d\t%s\t%f\t%f\n", p.PageID, p.Title, p.Lat, p.Lon)
+		}
+		return nil
+	}
+
+	if !c.IsSet("lat") || !c.IsSet("lon") {
+		return fmt.Errorf("missing --lat and --lon, or --bbox")
+	}
+
+	places, err := query.WithinRadius(db, c.GlobalString("language"), c.Float64("lat"), c.Float64("lon"), c.Float64("radius"))
+	if err != nil {
+		return err
+	}
+	for _, p := range places {
+		fmt.Printf("%d\t%s\t%f\t%f\t%.2fkm\n", p.PageID, p.Title, p.Lat, p.Lon, p.Distance)
+	}
+	return nil
+}
+
+func parseBox(s string) (geo.Box, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return geo.Box{}, fmt.Errorf("invalid bounding box '%s', expected min_lat,min_lon,max_lat,max_lon", s)
+	}
+
+	var v [4]float64
+	for i := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		if err != nil {
+			return geo.Box{}, fmt.Errorf("invalid bounding box '%s': %s", s, err)
+		}
+		v[i] = f
+	}
+
+	b := geo.Box{MinLat: v[0], MinLon: v[1], MaxLat: v[2], MaxLon: v[3]}
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLat > b.MaxLat {
+		return geo.Box{}, fmt.Errorf("invalid bounding box '%s': latitudes must be within -90 and 90, min_lat not above max_lat", s)
+	}
+	// min_lon greater than max_lon is a box crossing the antimeridian
+	if b.MinLon < -180 || b.MinLon > 180 || b.MaxLon < -180 || b.MaxLon > 180 {
+		return geo.Box{}, fmt.Errorf("invalid bounding box '%s': longitudes must be within -180 and 180", s)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/graph"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/importer"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+var graphCommand = cli.Command{
+	Name:  "graph",
+	Usage: "Export article reference graph as GraphML, GEXF or Neo4j import CSV",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:   "format",
+			Value:  "graphml",
+			Usage:  "Graph format ('graphml', 'gexf' or 'neo4j')",
+			EnvVar: "GRAPH_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   "source",
+			Value:  "db",
+			Usage:  "Read graph from database ('db') or parse it from dumps ('dump')",
+			EnvVar: "GRAPH_SOURCE",
+		},
+		cli.StringFlag{
+			Name:   "output-folder",
+			Value:  "./graph",
+			Usage:  "Folder receiving graph files",
+			EnvVar: "GRAPH_FOLDER",
+		},
+	},
+	Action: exportGraph,
+}
+
+func exportGraph(c *cli.Context) error {
+	source := c.String("source")
+	if source != "db" && source != "dump" {
+		return fmt.Errorf("unknown source '%s'", source)
+	}
+
+	err := setLogOutput(c)
+	if err != nil {
+		return err
+	}
+
+	w, err := graph.NewWriter(c.String("format"), c.String("output-folder"))
+	if err != nil {
+		return err
+	}
+
+	if source == "db" {
+		db, err := openDatabase(c)
+		if err != nil {
+			return err
+		}
+
+		err = graph.FromDB(db, c.GlobalString("language"), w)
+		if err != nil {
+			return err
+		}
+		return w.Close()
+	}
+
+	classifier, err := natureClassifier(c)
+	if err != nil {
+		return err
+	}
+
+	g, err := graph.NewDumpGraph(w, c.String("output-folder"), c.GlobalString("language"), classifier)
+	if err != nil {
+		return err
+	}
+
+	err = importer.Walk(c.GlobalString("dump-folder"), c.GlobalBool("tight"), c.GlobalBool("interactive"), c.GlobalString("language"), func(dumpName string, si *reader.SiteInfo, pagech chan reader.Page) error {
+		fmt.Printf("Reading graph from %s\n", dumpName)
+		return g.AddPages(pagech, parser.NewNamespaces(si))
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.Close()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/query"
+)
+
+var langlinksCommand = cli.Command{
+	Name:      "langlinks",
+	Usage:     "List all language versions of a page",
+	ArgsUsage: "<title>",
+	Action:    langlinks,
+}
+
+func langlinks(c *cli.Context) error {
+	title := strings.Join(c.Args(), " ")
+	if title == "" {
+		return fmt.Errorf("missing page title")
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	versions, err := query.LanguageVersions(db, c.GlobalString("language"), title)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		if v.PageID == 0 {
+			fmt.Printf("%s\t%s\t(not imported)\n", v.Wiki, v.Title)
+			continue
+		}
+		fmt.Printf("%s\t%s\t%d\n", v.Wiki, v.Title, v.PageID)
+	}
+
+	return nil
+}
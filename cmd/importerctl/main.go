@@ -4,12 +4,17 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 
 	_ "github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
 	"github.com/proullon/wikipedia-to-cockroachdb/pkg/importer"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/inserter"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/migration"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/sqlite"
 )
 
 func main() {
@@ -79,11 +84,118 @@ func main() {
 			Usage:  "Import page content",
 			EnvVar: "WITH_PAGE_CONTENT",
 		},
+		cli.StringFlag{
+			Name:   "page-content-format",
+			Value:  "wikitext",
+			Usage:  "Page content stored with --with-page-content ('wikitext', 'plaintext' or 'both')",
+			EnvVar: "PAGE_CONTENT_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   "plaintext-templates",
+			Value:  strings.Join(parser.DefaultPlaintextTemplates, ","),
+			Usage:  "Comma separated templates rendered in plain text, others are stripped ('lang-*' matches every 'lang-' template)",
+			EnvVar: "PLAINTEXT_TEMPLATES",
+		},
 		cli.BoolFlag{
 			Name:   "with-page-references",
 			Usage:  "Import page references",
 			EnvVar: "WITH_PAGE_REFERENCES",
 		},
+		cli.StringFlag{
+			Name:   "trailing-sections",
+			Usage:  "Comma separated titles of sections closing articles, such as 'See also', defaults depend on language",
+			EnvVar: "TRAILING_SECTIONS",
+		},
+		cli.BoolFlag{
+			Name:   "with-trailing-references",
+			Usage:  "Import references found in trailing sections too, flagged as trailing",
+			EnvVar: "WITH_TRAILING_REFERENCES",
+		},
+		cli.BoolFlag{
+			Name:   "with-reference-mentions",
+			Usage:  "Import reference anchors, displayed texts and occurrence offsets, used with --with-page-references",
+			EnvVar: "WITH_REFERENCE_MENTIONS",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-sections",
+			Usage:  "Import page section tree, with section text as plain text",
+			EnvVar: "WITH_PAGE_SECTIONS",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-infobox",
+			Usage:  "Import first infobox parameters of each page",
+			EnvVar: "WITH_PAGE_INFOBOX",
+		},
+		cli.BoolFlag{
+			Name:   "all-infoboxes",
+			Usage:  "Import parameters of every infobox of a page instead of the first one, used with --with-page-infobox",
+			EnvVar: "ALL_INFOBOXES",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-nature",
+			Usage:  "Classify pages (list, language, human, place) and import their nature and infobox name",
+			EnvVar: "WITH_PAGE_NATURE",
+		},
+		cli.StringFlag{
+			Name:   "nature-rules",
+			Usage:  "JSON rules file classifying pages per language, embedded rules are used if not set",
+			EnvVar: "NATURE_RULES",
+		},
+		cli.BoolFlag{
+			Name:   "with-categories",
+			Usage:  "Import category memberships, category pages and subcategory edges",
+			EnvVar: "WITH_CATEGORIES",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-templates",
+			Usage:  "Import template transclusions with their parameter count",
+			EnvVar: "WITH_PAGE_TEMPLATES",
+		},
+		cli.BoolFlag{
+			Name:   "page-template-params",
+			Usage:  "Also import template parameters as JSON, used with --with-page-templates",
+			EnvVar: "PAGE_TEMPLATE_PARAMS",
+		},
+		cli.BoolFlag{
+			Name:   "with-external-links",
+			Usage:  "Import external links and cited urls with their domain",
+			EnvVar: "WITH_EXTERNAL_LINKS",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-citations",
+			Usage:  "Import citation templates with their title, author, date, DOI, ISBN, url, publisher and access date",
+			EnvVar: "WITH_PAGE_CITATIONS",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-geo",
+			Usage:  "Import page coordinates from {{coord}} templates and infoboxes",
+			EnvVar: "WITH_PAGE_GEO",
+		},
+		cli.BoolFlag{
+			Name:   "with-disambiguations",
+			Usage:  "Mark disambiguation pages and import the articles they link to",
+			EnvVar: "WITH_DISAMBIGUATIONS",
+		},
+		cli.StringFlag{
+			Name:   "disambiguation-templates",
+			Usage:  "Comma separated templates marking disambiguation pages, defaults depend on language",
+			EnvVar: "DISAMBIGUATION_TEMPLATES",
+		},
+		cli.BoolFlag{
+			Name:   "exclude-disambiguation-references",
+			Usage:  "Remove references to disambiguation pages from article_reference, implies --with-disambiguations",
+			EnvVar: "EXCLUDE_DISAMBIGUATION_REFERENCES",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-stats",
+			Usage:  "Import page size, word, section, link, citation and image counts and revision timestamp",
+			EnvVar: "WITH_PAGE_STATS",
+		},
+		cli.BoolFlag{
+			Name:   "with-page-langlinks",
+			Usage:  "Import interlanguage links",
+			EnvVar: "WITH_PAGE_LANGLINKS",
+		},
 		cli.BoolFlag{
 			Name:   "interactive",
 			Usage:  "Select dump manually",
@@ -95,22 +207,191 @@ func main() {
 			Usage:  "Language to import (ie 'en', 'fr')",
 			EnvVar: "LANGUAGE",
 		},
+		cli.StringFlag{
+			Name:   "output",
+			Value:  "cockroachdb",
+			Usage:  "Import destination ('cockroachdb' or 'sqlite')",
+			EnvVar: "OUTPUT",
+		},
+		cli.StringFlag{
+			Name:   "sqlite-file",
+			Value:  "wikipedia.sqlite",
+			Usage:  "SQLite database file, used with --output=sqlite",
+			EnvVar: "SQLITE_FILE",
+		},
+		cli.BoolFlag{
+			Name:   "sqlite-fts-content",
+			Usage:  "Index page content in SQLite full text search, titles are always indexed",
+			EnvVar: "SQLITE_FTS_CONTENT",
+		},
 	}
 	app.Action = start
+	app.Commands = []cli.Command{
+		importCommand,
+		migrateCommand,
+		exportCommand,
+		graphCommand,
+		rdfCommand,
+		langlinksCommand,
+		classifyCommand,
+		categoriesCommand,
+		domainsCommand,
+		geoCommand,
+		collisionsCommand,
+		mentionsCommand,
+		dictionaryCommand,
+	}
 	err := app.Run(os.Args)
 	if err != nil {
 		fmt.Printf("Fatal error: %s", err)
 	}
 }
 
+var importCommand = cli.Command{
+	Name:   "import",
+	Usage:  "Import dumps into database (default command)",
+	Action: start,
+}
+
 func start(c *cli.Context) error {
+	parallelisationFactor := c.GlobalInt("db-max-conn")
+	output := c.GlobalString("output")
+	if output == "sqlite" {
+		// SQLite handles only one writer at a time
+		parallelisationFactor = 1
+	}
 
-	host := c.String("host")
-	dbname := c.String("dbname")
-	usr := c.String("user")
-	sslRootCert := c.String("ssl-root-cert")
-	sslClientKey := c.String("ssl-client-key")
-	sslClientCert := c.String("ssl-client-cert")
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	// SQLite file is owned by the importer, so it is migrated right away instead of asking for 'migrate'
+	if output == "sqlite" {
+		_, err = migration.Migrate(db, output)
+	} else {
+		err = migration.Check(db, output)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = setLogOutput(c)
+	if err != nil {
+		return err
+	}
+
+	opts := []inserter.Option{
+		inserter.WithPlaintextTemplates(strings.Split(c.GlobalString("plaintext-templates"), ",")),
+	}
+	if c.GlobalBool("with-page-content") {
+		opts = append(opts, inserter.WithPageContent())
+
+		switch format := c.GlobalString("page-content-format"); format {
+		case "wikitext":
+		case "plaintext", "both":
+			opts = append(opts, inserter.WithPlaintext(format == "both"))
+		default:
+			return fmt.Errorf("unknown page content format '%s'", format)
+		}
+	}
+	if c.GlobalBool("with-page-references") {
+		opts = append(opts, inserter.WithPageReferences())
+	}
+	if t := c.GlobalString("trailing-sections"); t != "" {
+		opts = append(opts, inserter.WithTrailingSections(strings.Split(t, ",")))
+	}
+	if c.GlobalBool("with-trailing-references") {
+		opts = append(opts, inserter.WithTrailingReferences())
+	}
+	if c.GlobalBool("with-reference-mentions") {
+		opts = append(opts, inserter.WithReferenceMentions())
+	}
+	if c.GlobalBool("with-page-sections") {
+		opts = append(opts, inserter.WithPageSections())
+	}
+	if c.GlobalBool("with-page-infobox") {
+		opts = append(opts, inserter.WithPageInfoboxes(c.GlobalBool("all-infoboxes")))
+	}
+	if c.GlobalBool("with-page-nature") {
+		classifier, err := natureClassifier(c)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, inserter.WithPageNature(classifier))
+	}
+	if c.GlobalBool("with-categories") {
+		opts = append(opts, inserter.WithCategories())
+	}
+	if c.GlobalBool("with-page-templates") {
+		opts = append(opts, inserter.WithPageTemplates(c.GlobalBool("page-template-params")))
+	}
+	if c.GlobalBool("with-external-links") {
+		opts = append(opts, inserter.WithExternalLinks())
+	}
+	if c.GlobalBool("with-page-citations") {
+		opts = append(opts, inserter.WithPageCitations())
+	}
+	if c.GlobalBool("with-page-geo") {
+		opts = append(opts, inserter.WithPageGeo())
+	}
+	if c.GlobalBool("with-disambiguations") {
+		opts = append(opts, inserter.WithDisambiguations())
+	}
+	if t := c.GlobalString("disambiguation-templates"); t != "" {
+		opts = append(opts, inserter.WithDisambiguationTemplates(strings.Split(t, ",")))
+	}
+	if c.GlobalBool("exclude-disambiguation-references") {
+		opts = append(opts, inserter.WithoutDisambiguationReferences())
+	}
+	if c.GlobalBool("with-page-stats") {
+		opts = append(opts, inserter.WithPageStats())
+	}
+	if c.GlobalBool("with-page-langlinks") {
+		opts = append(opts, inserter.WithPageLangLinks())
+	}
+
+	err = importer.Import(db, c.GlobalString("dump-folder"), parallelisationFactor, c.GlobalBool("tight"), c.GlobalBool("interactive"), c.GlobalString("language"), opts...)
+	if err != nil {
+		return err
+	}
+
+	if output == "sqlite" {
+		err = sqlite.BuildFullTextIndex(db, c.GlobalBool("with-page-content") && c.GlobalBool("sqlite-fts-content"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openDatabase opens the database selected by global --output flag
+func openDatabase(c *cli.Context) (*sql.DB, error) {
+	output := c.GlobalString("output")
+
+	switch output {
+	case "cockroachdb":
+		return openCockroachDB(c)
+	case "sqlite":
+		db, err := sqlite.Open(c.GlobalString("sqlite-file"))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Opened %s\n", c.GlobalString("sqlite-file"))
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown output '%s'", output)
+	}
+}
+
+func openCockroachDB(c *cli.Context) (*sql.DB, error) {
+	host := c.GlobalString("host")
+	dbname := c.GlobalString("dbname")
+	usr := c.GlobalString("user")
+	sslRootCert := c.GlobalString("ssl-root-cert")
+	sslClientKey := c.GlobalString("ssl-client-key")
+	sslClientCert := c.GlobalString("ssl-client-cert")
 
 	dsn := fmt.Sprintf("postgresql://%s@%s:26257/%s?ssl=true&sslmode=require&sslrootcert=%s&sslkey=%s&sslcert=%s",
 		usr,
@@ -123,25 +404,25 @@ func start(c *cli.Context) error {
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	err = db.Ping()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	db.SetMaxOpenConns(c.Int("db-max-conn"))
+	db.SetMaxOpenConns(c.GlobalInt("db-max-conn"))
 	db.SetMaxIdleConns(0)
 	fmt.Printf("Connected to %s/%s\n", host, dbname)
 
-	f, err := os.OpenFile(c.String("logfile"), os.O_WRONLY|os.O_CREATE, 0755)
-	if err != nil {
-		return err
-	}
-	log.SetOutput(f)
+	return db, nil
+}
 
-	err = importer.Import(db, c.String("dump-folder"), c.Int("db-max-conn"), c.Bool("tight"), c.Bool("with-page-content"), c.Bool("with-page-references"), c.Bool("interactive"), c.String("language"))
+// setLogOutput redirects logs to global --logfile
+func setLogOutput(c *cli.Context) error {
+	f, err := os.OpenFile(c.GlobalString("logfile"), os.O_WRONLY|os.O_CREATE, 0755)
 	if err != nil {
 		return err
 	}
+	log.SetOutput(f)
 	return nil
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/query"
+)
+
+var mentionsCommand = cli.Command{
+	Name:  "mentions",
+	Usage: "Export mention to entity dictionary from imported reference surface forms, as TSV: surface, page id, title, count",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "Output file, standard output if empty",
+		},
+		cli.IntFlag{
+			Name:  "min-count",
+			Value: 1,
+			Usage: "Minimum number of links of a surface form to a page",
+		},
+	},
+	Action: mentions,
+}
+
+func mentions(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if c.String("output") != "" {
+		out, err = os.Create(c.String("output"))
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	w := bufio.NewWriter(out)
+	err = query.Mentions(db, c.GlobalString("language"), func(mentions []query.Mention) error {
+		for _, m := range mentions {
+			if m.Count < c.Int("min-count") {
+				continue
+			}
+			_, err := fmt.Fprintf(w, "%s\t%d\t%s\t%d\n", m.Surface, m.PageID, m.Title, m.Count)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
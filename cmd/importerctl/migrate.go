@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/migration"
+)
+
+var migrateCommand = cli.Command{
+	Name:   "migrate",
+	Usage:  "Apply pending schema migrations",
+	Action: migrate,
+}
+
+func migrate(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	output := c.GlobalString("output")
+	version, err := migration.Version(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema version %d\n", version)
+
+	n, err := migration.Migrate(db, output)
+	if err != nil {
+		return err
+	}
+
+	version, err = migration.Version(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d migrations, schema version %d\n", n, version)
+
+	return nil
+}
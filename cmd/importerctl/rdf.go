@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/rdf"
+)
+
+var rdfCommand = cli.Command{
+	Name:  "rdf",
+	Usage: "Export imported pages and references as gzip compressed N-Triples or Turtle",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:   "format",
+			Value:  "nt",
+			Usage:  "RDF serialization ('nt' or 'ttl')",
+			EnvVar: "RDF_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   "output-file",
+			Value:  "wikipedia.nt.gz",
+			Usage:  "Gzip compressed output file",
+			EnvVar: "RDF_FILE",
+		},
+	},
+	Action: exportRDF,
+}
+
+func exportRDF(c *cli.Context) error {
+	err := setLogOutput(c)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(c.String("output-file"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	language := c.GlobalString("language")
+	w, err := rdf.NewWriter(f, c.String("format"), language)
+	if err != nil {
+		return err
+	}
+
+	err = rdf.FromDB(db, language, w)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
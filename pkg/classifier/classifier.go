@@ -0,0 +1,250 @@
+package classifier
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// defaultRules are used unless a rules file is given, see Default
+//
+//go:embed rules.json
+var defaultRules []byte
+
+// Rules lists classification rules per language
+type Rules map[string][]Rule
+
+// Rule maps page features to a nature. A rule matches if any of its criteria matches, matching rule with
+// highest priority giving page nature.
+type Rule struct {
+	Name     string `json:"name"`
+	Nature   string `json:"nature"`
+	Priority int    `json:"priority"`
+
+	// Infoboxes matches infobox types containing one of them as whole words, such as 'settlement' for
+	// {{Infobox settlement}} or {{Infobox UK settlement}}, but not 'port' for {{Infobox sport}}
+	Infoboxes []string `json:"infoboxes,omitempty"`
+	// Fields matches infobox parameter names, such as 'birth_date'
+	Fields []string `json:"fields,omitempty"`
+	// Templates matches template names
+	Templates []string `json:"templates,omitempty"`
+	// Categories matches categories containing one of them as whole words, such as 'births' for [[Category:1950 births]]
+	Categories []string `json:"categories,omitempty"`
+	// TitlePrefixes matches page titles starting with one of them followed by a word boundary, such as 'list of
+	TitlePrefixes []string `json:"title_prefixes,omitempty"`
+
+	nature parser.Nature
+}
+
+// Classifier classifies pages of a language with rules
+type Classifier struct {
+	rules []Rule
+}
+
+// New creates a Classifier using rules of language, english rules being used if language has none
+func New(rules Rules, language string) (*Classifier, error) {
+	r, ok := rules[language]
+	if !ok {
+		r = rules["en"]
+	}
+
+	c := &Classifier{rules: make([]Rule, len(r))}
+	for i, rule := range r {
+		nature, err := parser.ParseNature(rule.Nature)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s': %s", rule.Name, err)
+		}
+		rule.nature = nature
+		rule.Infoboxes = lower(rule.Infoboxes)
+		rule.Fields = lower(rule.Fields)
+		rule.Templates = templateNames(rule.Templates)
+		rule.Categories = lower(rule.Categories)
+		rule.TitlePrefixes = lower(rule.TitlePrefixes)
+		c.rules[i] = rule
+	}
+
+	// first declared rule wins among rules of same priority
+	sort.SliceStable(c.rules, func(i, j int) bool {
+		return c.rules[i].Priority > c.rules[j].Priority
+	})
+
+	return c, nil
+}
+
+// Load creates a Classifier from a JSON rules file
+func Load(filename string, language string) (*Classifier, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(b, language)
+}
+
+// Default creates a Classifier from embedded rules
+func Default(language string) (*Classifier, error) {
+	return parse(defaultRules, language)
+}
+
+func parse(b []byte, language string) (*Classifier, error) {
+	var rules Rules
+	err := json.Unmarshal(b, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("rules: %s", err)
+	}
+
+	return New(rules, language)
+}
+
+// Classify returns nature of parsed page p and the rule giving it, nil if no rule matches. Templates and categories
+// are recognized from wiki namespaces ns.
+func (c *Classifier) Classify(p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) (parser.Nature, *Rule) {
+	f := extract(p, nodes, ns)
+
+	for i := range c.rules {
+		if f.match(&c.rules[i]) {
+			return c.rules[i].nature, &c.rules[i]
+		}
+	}
+
+	return parser.UnknownNature, nil
+}
+
+// features are page properties rules are matched against
+type features struct {
+	title      string
+	infoboxes  []string
+	fields     map[string]bool
+	templates  map[string]bool
+	categories []string
+}
+
+func extract(p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) *features {
+	f := &features{
+		title:     strings.ToLower(p.Title),
+		fields:    make(map[string]bool),
+		templates: make(map[string]bool),
+	}
+
+	parser.Walk(nodes, func(n *parser.Node) bool {
+		switch n.Type {
+		case parser.TemplateNode:
+			f.templates[ns.TemplateName(n.Name)] = true
+			if parser.IsInfobox(n, ns) {
+				f.infoboxes = append(f.infoboxes, parser.InfoboxType(n.Name, ns))
+				for _, param := range n.Params {
+					f.fields[strings.ToLower(strings.TrimSpace(param.Name))] = true
+				}
+			}
+		case parser.LinkNode:
+			if name, ok := parser.CategoryName(n, ns); ok {
+				f.categories = append(f.categories, strings.ToLower(name))
+			}
+		}
+		return true
+	})
+
+	return f
+}
+
+func (f *features) match(r *Rule) bool {
+	for _, prefix := range r.TitlePrefixes {
+		if hasWordPrefix(f.title, prefix) {
+			return true
+		}
+	}
+
+	for _, infobox := range r.Infoboxes {
+		for _, t := range f.infoboxes {
+			if containsWords(t, infobox) {
+				return true
+			}
+		}
+	}
+
+	for _, field := range r.Fields {
+		if f.fields[field] {
+			return true
+		}
+	}
+
+	for _, template := range r.Templates {
+		if f.templates[template] {
+			return true
+		}
+	}
+
+	for _, category := range r.Categories {
+		for _, c := range f.categories {
+			if containsWords(c, category) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasWordPrefix returns true if s starts with prefix, prefix not ending in the middle of a word of s
+func hasWordPrefix(s string, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(s[len(prefix):])
+	return len(s) == len(prefix) || !isWordRune(next)
+}
+
+// containsWords returns true if words of phrase appear in s, in a row
+func containsWords(s string, phrase string) bool {
+	sw, pw := strings.FieldsFunc(s, isSeparator), strings.FieldsFunc(phrase, isSeparator)
+	if len(pw) == 0 {
+		return false
+	}
+
+	for i := 0; i+len(pw) <= len(sw); i++ {
+		match := true
+		for j := range pw {
+			if sw[i+j] != pw[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isSeparator(r rune) bool {
+	return !isWordRune(r)
+}
+
+func lower(s []string) []string {
+	l := make([]string, len(s))
+	for i := range s {
+		l[i] = strings.ToLower(strings.TrimSpace(s[i]))
+	}
+	return l
+}
+
+// templateNames returns normalized copies of configured template names, see parser.Namespaces.TemplateName
+func templateNames(s []string) []string {
+	names := make([]string, len(s))
+	for i := range s {
+		names[i] = parser.DefaultNamespaces.TemplateName(s[i])
+	}
+	return names
+}
@@ -0,0 +1,86 @@
+package classifier
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+)
+
+// Confusion counts pages by expected and actual nature, and how often each rule matched
+type Confusion struct {
+	counts map[[2]parser.Nature]int
+	rules  map[string]int
+	total  int
+}
+
+func NewConfusion() *Confusion {
+	return &Confusion{
+		counts: make(map[[2]parser.Nature]int),
+		rules:  make(map[string]int),
+	}
+}
+
+// Add counts a page expected to be of nature expected, classified as actual by rule, which is nil if no rule matched
+func (c *Confusion) Add(expected parser.Nature, actual parser.Nature, rule *Rule) {
+	c.counts[[2]parser.Nature{expected, actual}]++
+	c.total++
+
+	name := "(none)"
+	if rule != nil {
+		name = rule.Name
+	}
+	c.rules[name]++
+}
+
+// Total returns number of pages counted
+func (c *Confusion) Total() int {
+	return c.total
+}
+
+// Print writes confusion matrix, expected natures as rows and actual natures as columns, followed by agreement and rule hits
+func (c *Confusion) Print(w io.Writer) error {
+	natures := []parser.Nature{parser.UnknownNature, parser.ListNature, parser.LanguageNature, parser.HumanNature, parser.PlaceNature}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "expected \\ actual\t")
+	for _, n := range natures {
+		fmt.Fprintf(tw, "%s\t", n)
+	}
+	fmt.Fprintln(tw)
+
+	var agree int
+	for _, e := range natures {
+		fmt.Fprintf(tw, "%s\t", e)
+		for _, a := range natures {
+			fmt.Fprintf(tw, "%d\t", c.counts[[2]parser.Nature{e, a}])
+		}
+		fmt.Fprintln(tw)
+		agree += c.counts[[2]parser.Nature{e, e}]
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if c.total > 0 {
+		fmt.Fprintf(w, "\nAgreement: %d/%d (%.1f%%)\n", agree, c.total, 100*float64(agree)/float64(c.total))
+	}
+
+	names := make([]string, 0, len(c.rules))
+	for name := range c.rules {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return c.rules[names[i]] > c.rules[names[j]]
+	})
+
+	fmt.Fprintf(w, "\nRule hits:\n")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%d\n", name, c.rules[name])
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,200 @@
+// Package dictionary builds an anchor text dictionary: for each phrase used as link text, the pages it links to with
+// their count, and its link probability, how often the phrase is linked among articles containing it.
+//
+// Pages are read in a single streaming pass. Counts are held in memory up to a bounded number of keys, then spilled
+// to sorted run files merged once every page is read, so memory does not grow with wiki size.
+package dictionary
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// spooled key kinds, keys being 'phrase \t kind \t target'
+const (
+	linkedKind = "d" // article links phrase at least once
+	linkKind   = "l" // phrase links to target, counting occurences
+	textKind   = "t" // article contains phrase, linked or not
+)
+
+// Builder accumulates phrases of pages. Phrases are lowercased words separated by a space, punctuation removed.
+type Builder struct {
+	maxWords  int
+	templates []string
+	spool     *spool
+}
+
+// New creates a Builder spilling counts in folder once maxKeys distinct keys are held in memory. Phrases of up to
+// maxWords words are counted in text, longer link texts getting a link probability of 1.
+func New(folder string, maxWords int, maxKeys int) *Builder {
+	return &Builder{
+		maxWords:  maxWords,
+		templates: parser.DefaultPlaintextTemplates,
+		spool:     newSpool(folder, maxKeys),
+	}
+}
+
+// AddPage counts link texts and phrases of parsed page p
+func (b *Builder) AddPage(p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) error {
+	linked := make(map[string]bool)
+	for _, ref := range parser.References(nodes, nil, ns) {
+		for surface, count := range ref.Surfaces {
+			phrase := Phrase(surface)
+			if phrase == "" {
+				continue
+			}
+			linked[phrase] = true
+
+			err := b.spool.add(phrase+"\t"+linkKind+"\t"+ref.Title, count)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for phrase := range linked {
+		err := b.spool.add(phrase+"\t"+linkedKind+"\t", 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	// each phrase is counted once per article, articles without links included since they lower link probability
+	words := Words(parser.Plaintext(nodes, b.templates, ns))
+	seen := make(map[string]bool)
+	for i := range words {
+		for n := 1; n <= b.maxWords && i+n <= len(words); n++ {
+			phrase := strings.Join(words[i:i+n], " ")
+			if seen[phrase] {
+				continue
+			}
+			seen[phrase] = true
+
+			err := b.spool.add(phrase+"\t"+textKind+"\t", 1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Entry is a phrase linking to a page. Commonness is the share of phrase links pointing to page, LinkProbability the
+// share of articles containing phrase where it is a link.
+type Entry struct {
+	Phrase          string
+	Title           string
+	Count           int
+	Commonness      float64
+	LinkProbability float64
+}
+
+// Build merges counted phrases and calls fn with entries of each linked phrase, most linked pages first.
+// Entries linked less than minCount times are skipped. Spilled files are removed once done.
+func (b *Builder) Build(minCount int, fn func([]Entry) error) error {
+	defer b.spool.close()
+
+	var phrase string
+	var linkedDocs, textDocs, links int
+	var entries []Entry
+
+	flush := func() error {
+		if len(entries) == 0 {
+			return nil
+		}
+
+		// link texts longer than maxWords are not counted in text
+		if textDocs < linkedDocs {
+			textDocs = linkedDocs
+		}
+
+		var kept []Entry
+		for _, e := range entries {
+			if e.Count < minCount {
+				continue
+			}
+			e.Commonness = float64(e.Count) / float64(links)
+			e.LinkProbability = float64(linkedDocs) / float64(textDocs)
+			kept = append(kept, e)
+		}
+		entries = nil
+
+		if len(kept) == 0 {
+			return nil
+		}
+		sort.SliceStable(kept, func(i, j int) bool {
+			return kept[i].Count > kept[j].Count
+		})
+		return fn(kept)
+	}
+
+	err := b.spool.merge(func(key string, count int) error {
+		t := strings.SplitN(key, "\t", 3)
+		if len(t) != 3 {
+			return fmt.Errorf("invalid key '%s'", key)
+		}
+
+		if t[0] != phrase {
+			err := flush()
+			if err != nil {
+				return err
+			}
+			phrase = t[0]
+			linkedDocs, textDocs, links = 0, 0, 0
+		}
+
+		switch t[1] {
+		case linkedKind:
+			linkedDocs += count
+		case linkKind:
+			links += count
+			entries = append(entries, Entry{Phrase: phrase, Title: t[2], Count: count})
+		case textKind:
+			textDocs += count
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// WriteTSV writes entries of Build as TSV: phrase, page title, count, commonness and link probability
+func (b *Builder) WriteTSV(w io.Writer, minCount int) error {
+	return b.Build(minCount, func(entries []Entry) error {
+		for _, e := range entries {
+			_, err := fmt.Fprintf(w, "%s\t%s\t%d\t%.4f\t%.4f\n", e.Phrase, e.Title, e.Count, e.Commonness, e.LinkProbability)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close removes spilled files, for builds interrupted before Build
+func (b *Builder) Close() {
+	b.spool.close()
+}
+
+// Phrase returns s lowercased, words separated by a single space and punctuation removed
+func Phrase(s string) string {
+	return strings.Join(Words(s), " ")
+}
+
+// Words returns lowercased words of s, letters and digits sequences. HTML entities are decoded first.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(html.UnescapeString(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
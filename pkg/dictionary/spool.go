@@ -0,0 +1,237 @@
+package dictionary
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// spool counts keys in memory, spilling them to sorted run files on disk once maxKeys distinct keys are held.
+// Runs are merged back in key order, counts of identical keys being summed.
+type spool struct {
+	folder  string
+	maxKeys int
+	counts  map[string]int
+	runs    []string
+}
+
+func newSpool(folder string, maxKeys int) *spool {
+	return &spool{
+		folder:  folder,
+		maxKeys: maxKeys,
+		counts:  make(map[string]int),
+	}
+}
+
+func (s *spool) add(key string, n int) error {
+	s.counts[key] += n
+	if len(s.counts) >= s.maxKeys {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill writes counted keys, sorted, to a new run file
+func (s *spool) spill() error {
+	if len(s.counts) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(s.counts))
+	for k := range s.counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f, err := os.CreateTemp(s.folder, "dictionary-run-*.tsv")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s.runs = append(s.runs, f.Name())
+
+	w := bufio.NewWriter(f)
+	for _, k := range keys {
+		_, err = fmt.Fprintf(w, "%s\t%d\n", k, s.counts[k])
+		if err != nil {
+			return err
+		}
+	}
+
+	s.counts = make(map[string]int)
+	return w.Flush()
+}
+
+// mergeFanIn is the maximum number of run files open at once while merging
+const mergeFanIn = 64
+
+// merge calls fn with every key and its total count, in key order. Runs are first merged mergeFanIn at a time into
+// larger runs, until few enough are left to be merged at once.
+func (s *spool) merge(fn func(key string, count int) error) error {
+	err := s.spill()
+	if err != nil {
+		return err
+	}
+
+	for len(s.runs) > mergeFanIn {
+		var runs []string
+		for i := 0; i < len(s.runs); i += mergeFanIn {
+			end := i + mergeFanIn
+			if end > len(s.runs) {
+				end = len(s.runs)
+			}
+
+			name, err := s.mergeRun(s.runs[i:end])
+			if err != nil {
+				return err
+			}
+			runs = append(runs, name)
+		}
+
+		// merged runs are removed, spool only tracks the ones left
+		for _, name := range s.runs {
+			os.Remove(name)
+		}
+		s.runs = runs
+	}
+
+	return mergeRuns(s.runs, fn)
+}
+
+// mergeRun merges runs into a new run file and returns its name
+func (s *spool) mergeRun(runs []string) (string, error) {
+	f, err := os.CreateTemp(s.folder, "dictionary-run-*.tsv")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	err = mergeRuns(runs, func(key string, count int) error {
+		_, err := fmt.Fprintf(w, "%s\t%d\n", key, count)
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// mergeRuns calls fn with every key of run files and its total count, in key order. Each file is closed as soon as
+// it is exhausted.
+func mergeRuns(runs []string, fn func(key string, count int) error) error {
+	h := &runHeap{}
+	// runs still open are closed if merge stops early
+	defer func() {
+		for _, r := range *h {
+			r.f.Close()
+		}
+	}()
+
+	for _, name := range runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		r := &run{f: f, scanner: bufio.NewScanner(f)}
+		r.scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		ok, err := r.next()
+		if err != nil || !ok {
+			f.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		heap.Push(h, r)
+	}
+
+	var key string
+	var count int
+	for h.Len() > 0 {
+		r := (*h)[0]
+		if r.key != key && count > 0 {
+			err := fn(key, count)
+			if err != nil {
+				return err
+			}
+			count = 0
+		}
+		key = r.key
+		count += r.count
+
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+			r.f.Close()
+		}
+	}
+	if count > 0 {
+		return fn(key, count)
+	}
+
+	return nil
+}
+
+// close removes run files
+func (s *spool) close() {
+	for _, name := range s.runs {
+		os.Remove(name)
+	}
+	s.runs = nil
+}
+
+// run is a sorted run file being merged, key and count holding its current line
+type run struct {
+	f       *os.File
+	scanner *bufio.Scanner
+	key     string
+	count   int
+}
+
+func (r *run) next() (bool, error) {
+	if !r.scanner.Scan() {
+		return false, r.scanner.Err()
+	}
+
+	line := r.scanner.Text()
+	i := strings.LastIndexByte(line, '\t')
+	if i < 0 {
+		return false, fmt.Errorf("invalid run line '%s'", line)
+	}
+
+	count, err := strconv.Atoi(line[i+1:])
+	if err != nil {
+		return false, fmt.Errorf("invalid run line '%s': %s", line, err)
+	}
+	r.key, r.count = line[:i], count
+	return true, nil
+}
+
+type runHeap []*run
+
+func (h runHeap) Len() int            { return len(h) }
+func (h runHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h runHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*run)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
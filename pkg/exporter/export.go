@@ -0,0 +1,173 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+const (
+	manifestFile = "manifest.json"
+)
+
+// Manifest describes an export, written in output folder once export is done
+type Manifest struct {
+	Wiki          string    `json:"wiki"`
+	Format        string    `json:"format"`
+	Compression   string    `json:"compression"`
+	SchemaVersion int       `json:"schema_version"`
+	Columns       []string  `json:"columns"`
+	Dumps         []string  `json:"dumps"`
+	Shards        []Shard   `json:"shards"`
+	Rows          int       `json:"rows"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Shard is an output file and the number of rows it contains
+type Shard struct {
+	File string `json:"file"`
+	Rows int    `json:"rows"`
+}
+
+// Exporter streams pages into sharded files, starting a new shard every shardSize rows
+type Exporter struct {
+	folder    string
+	format    string
+	ext       string
+	shardSize int
+	trailing  []string
+
+	w        ShardWriter
+	manifest Manifest
+}
+
+// New creates an Exporter of wiki pages. References found in wiki trailing sections, such as See also, are not exported.
+func New(folder string, format string, shardSize int, wiki string) (*Exporter, error) {
+	ext, err := Extension(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if shardSize <= 0 {
+		return nil, fmt.Errorf("invalid shard size %d", shardSize)
+	}
+
+	err = os.MkdirAll(folder, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exporter{
+		folder:    folder,
+		format:    format,
+		ext:       ext,
+		shardSize: shardSize,
+		trailing:  parser.TrailingSectionTitles(wiki),
+		manifest: Manifest{
+			Wiki:          wiki,
+			Format:        format,
+			Compression:   Compression(format),
+			SchemaVersion: SchemaVersion,
+			Columns:       Columns,
+			Dumps:         []string{},
+			Shards:        []Shard{},
+		},
+	}
+
+	return e, nil
+}
+
+// Export writes every article of pagech, references being parsed with dump namespaces ns. Meta pages are skipped, as
+// they are on import. Shards are shared between dumps, so several dumps can be exported in a row.
+func (e *Exporter) Export(dumpName string, ns *parser.Namespaces, pagech chan reader.Page) error {
+	e.manifest.Dumps = append(e.manifest.Dumps, dumpName)
+
+	for p := range pagech {
+		if parser.IsMeta(&p) {
+			continue
+		}
+
+		err := e.write(NewRecord(&p, e.trailing, ns))
+		if err != nil {
+			// drain channel so reader goroutine can exit
+			for range pagech {
+			}
+			return fmt.Errorf("exporting %s (%d): %s", p.Title, p.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Exporter) write(r *Record) error {
+	var err error
+
+	if e.w == nil {
+		err = e.openShard()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = e.w.Write(r)
+	if err != nil {
+		return err
+	}
+
+	shard := &e.manifest.Shards[len(e.manifest.Shards)-1]
+	shard.Rows++
+	e.manifest.Rows++
+
+	if shard.Rows >= e.shardSize {
+		return e.closeShard()
+	}
+
+	return nil
+}
+
+func (e *Exporter) openShard() error {
+	filename := fmt.Sprintf("pages-%05d%s", len(e.manifest.Shards), e.ext)
+
+	w, err := newShardWriter(e.format, path.Join(e.folder, filename))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Opened shard %s", filename)
+	e.w = w
+	e.manifest.Shards = append(e.manifest.Shards, Shard{File: filename})
+	return nil
+}
+
+func (e *Exporter) closeShard() error {
+	if e.w == nil {
+		return nil
+	}
+
+	err := e.w.Close()
+	e.w = nil
+	return err
+}
+
+// Close flushes current shard and writes manifest
+func (e *Exporter) Close() error {
+	err := e.closeShard()
+	if err != nil {
+		return err
+	}
+
+	e.manifest.CreatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(e.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(e.folder, manifestFile), data, 0644)
+}
@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"sort"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// SchemaVersion is bumped each time Record fields or their meaning change, so consumers can detect it from manifest.
+// Version 2 only exports articles, and reference titles are normalized with wiki case and namespaces.
+const SchemaVersion = 2
+
+// Record is the exported representation of a page. Field names are the stable schema shared by all formats.
+type Record struct {
+	PageID     int         `json:"page_id" parquet:"page_id"`
+	Title      string      `json:"title" parquet:"title"`
+	Text       string      `json:"text" parquet:"text"`
+	References []Reference `json:"references" parquet:"references,list"`
+}
+
+// Reference is an exported article reference, identified by title since dumps have no page id for them.
+type Reference struct {
+	Title      string `json:"title" parquet:"title"`
+	Occurrence int    `json:"occurrence" parquet:"occurrence"`
+	Index      int    `json:"reference_index" parquet:"reference_index"`
+}
+
+// Columns lists Record fields in export order
+var Columns = []string{"page_id", "title", "text", "references"}
+
+// NewRecord builds a Record from page, parsing its references with wiki namespaces ns. References in trailing
+// sections are skipped. References are sorted by index so output is deterministic.
+func NewRecord(p *reader.Page, trailing []string, ns *parser.Namespaces) *Record {
+	r := &Record{
+		PageID:     p.ID,
+		Title:      p.Title,
+		Text:       p.Text,
+		References: []Reference{},
+	}
+
+	for _, ref := range parser.PageReferences(p, trailing, ns) {
+		if ref.Trailing {
+			continue
+		}
+		r.References = append(r.References, Reference{
+			Title:      ref.Title,
+			Occurrence: ref.Occurence,
+			Index:      ref.Index,
+		})
+	}
+	sort.Slice(r.References, func(i, j int) bool {
+		return r.References[i].Index < r.References[j].Index
+	})
+
+	return r
+}
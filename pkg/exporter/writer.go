@@ -0,0 +1,137 @@
+package exporter
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ShardWriter writes records to a single shard file
+type ShardWriter interface {
+	Write(r *Record) error
+	Close() error
+}
+
+// Extension returns shard file extension for given format
+func Extension(format string) (string, error) {
+	switch format {
+	case "jsonl":
+		return ".jsonl.gz", nil
+	case "csv":
+		return ".csv.gz", nil
+	case "parquet":
+		return ".parquet", nil
+	default:
+		return "", fmt.Errorf("unknown format '%s'", format)
+	}
+}
+
+// Compression returns compression codec used for given format
+func Compression(format string) string {
+	if format == "parquet" {
+		return "snappy"
+	}
+	return "gzip"
+}
+
+func newShardWriter(format string, filename string) (ShardWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "jsonl":
+		gz := gzip.NewWriter(f)
+		return &jsonlWriter{f: f, gz: gz, enc: json.NewEncoder(gz)}, nil
+	case "csv":
+		gz := gzip.NewWriter(f)
+		w := &csvWriter{f: f, gz: gz, w: csv.NewWriter(gz)}
+		err = w.w.Write(Columns)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return w, nil
+	case "parquet":
+		w := parquet.NewGenericWriter[Record](f, parquet.Compression(&parquet.Snappy))
+		return &parquetWriter{f: f, w: w}, nil
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unknown format '%s'", format)
+	}
+}
+
+type jsonlWriter struct {
+	f   *os.File
+	gz  *gzip.Writer
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) Write(r *Record) error {
+	return w.enc.Encode(r)
+}
+
+func (w *jsonlWriter) Close() error {
+	err := w.gz.Close()
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// csvWriter flattens references into a JSON array column
+type csvWriter struct {
+	f  *os.File
+	gz *gzip.Writer
+	w  *csv.Writer
+}
+
+func (w *csvWriter) Write(r *Record) error {
+	refs, err := json.Marshal(r.References)
+	if err != nil {
+		return err
+	}
+
+	return w.w.Write([]string{strconv.Itoa(r.PageID), r.Title, r.Text, string(refs)})
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	err := w.w.Error()
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+	err = w.gz.Close()
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+type parquetWriter struct {
+	f *os.File
+	w *parquet.GenericWriter[Record]
+}
+
+func (w *parquetWriter) Write(r *Record) error {
+	_, err := w.w.Write([]Record{*r})
+	return err
+}
+
+func (w *parquetWriter) Close() error {
+	err := w.w.Close()
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
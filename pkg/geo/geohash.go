@@ -0,0 +1,177 @@
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// Precision is the geohash length stored for each coordinate, about 5 meters
+const Precision = 9
+
+// EarthRadius is the mean earth radius in kilometers
+const EarthRadius = 6371.0
+
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// maxCells bounds the number of geohash cells covering a box, and so the number of range queries
+const maxCells = 16
+
+// Encode returns geohash of given precision of coordinate lat, lon
+func Encode(lat, lon float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	var b strings.Builder
+	var bit, ch int
+	even := true
+	for b.Len() < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if lon >= mid {
+				ch |= 1 << (4 - bit)
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		bit++
+		if bit == 5 {
+			b.WriteByte(base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return b.String()
+}
+
+// CellSize returns height and width in degrees of geohash cells of given precision
+func CellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// Box is a bounding box in decimal degrees. MinLon is greater than MaxLon for boxes crossing the antimeridian.
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Contains returns true if lat, lon is inside box
+func (b Box) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon > b.MaxLon {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// Cover returns geohash prefixes of cells covering box, using the longest precision needing at most a few cells.
+// Every coordinate inside box has a geohash starting with one of them.
+func Cover(b Box) []string {
+	if b.MinLon > b.MaxLon {
+		west := Cover(Box{MinLat: b.MinLat, MinLon: b.MinLon, MaxLat: b.MaxLat, MaxLon: 180})
+		east := Cover(Box{MinLat: b.MinLat, MinLon: -180, MaxLat: b.MaxLat, MaxLon: b.MaxLon})
+		return append(west, east...)
+	}
+
+	for precision := Precision; precision > 1; precision-- {
+		h, w := CellSize(precision)
+		rows := math.Floor((b.MaxLat-b.MinLat)/h) + 2
+		cols := math.Floor((b.MaxLon-b.MinLon)/w) + 2
+		if rows*cols <= maxCells {
+			return cells(b, precision, h, w)
+		}
+	}
+
+	h, w := CellSize(1)
+	return cells(b, 1, h, w)
+}
+
+// cells returns geohashes of cells intersecting box, sampling it every cell height and width, box edges included
+func cells(b Box, precision int, h, w float64) []string {
+	var hashes []string
+	seen := make(map[string]bool)
+
+	for lat := b.MinLat; ; lat += h {
+		if lat > b.MaxLat {
+			lat = b.MaxLat
+		}
+		for lon := b.MinLon; ; lon += w {
+			if lon > b.MaxLon {
+				lon = b.MaxLon
+			}
+			hash := Encode(lat, lon, precision)
+			if !seen[hash] {
+				seen[hash] = true
+				hashes = append(hashes, hash)
+			}
+			if lon >= b.MaxLon {
+				break
+			}
+		}
+		if lat >= b.MaxLat {
+			break
+		}
+	}
+
+	return hashes
+}
+
+// Distance returns great circle distance in kilometers between two coordinates, using haversine formula
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLon := radians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// RadiusBox returns bounding box of circle of radius kilometers around lat, lon
+func RadiusBox(lat, lon, radius float64) Box {
+	dLat := degrees(radius / EarthRadius)
+	b := Box{MinLat: lat - dLat, MaxLat: lat + dLat}
+
+	// circle includes a pole, every longitude is in box
+	if b.MinLat <= -90 || b.MaxLat >= 90 {
+		b.MinLat = math.Max(b.MinLat, -90)
+		b.MaxLat = math.Min(b.MaxLat, 90)
+		b.MinLon, b.MaxLon = -180, 180
+		return b
+	}
+
+	dLon := degrees(math.Asin(math.Min(1, math.Sin(radius/EarthRadius)/math.Cos(radians(lat)))))
+	b.MinLon, b.MaxLon = lon-dLon, lon+dLon
+	if dLon >= 180 {
+		b.MinLon, b.MaxLon = -180, 180
+	}
+	if b.MinLon < -180 {
+		b.MinLon += 360
+	}
+	if b.MaxLon > 180 {
+		b.MaxLon -= 360
+	}
+	return b
+}
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+func degrees(r float64) float64 {
+	return r * 180 / math.Pi
+}
@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCover(t *testing.T) {
+	cases := []struct {
+		name   string
+		box    Box
+		points [][2]float64
+	}{
+		{"small box", Box{MinLat: 48.85, MinLon: 2.34, MaxLat: 48.87, MaxLon: 2.36},
+			[][2]float64{{48.85, 2.34}, {48.87, 2.36}, {48.86, 2.35}, {48.85, 2.36}}},
+		// geohash cells split at the equator and the prime meridian
+		{"cell edges", Box{MinLat: -0.01, MinLon: -0.01, MaxLat: 0.01, MaxLon: 0.01},
+			[][2]float64{{0, 0}, {-0.001, -0.001}, {0.01, -0.01}, {-0.01, 0.01}, {0, 0.005}}},
+		{"large box", Box{MinLat: 40, MinLon: -10, MaxLat: 55, MaxLon: 20},
+			[][2]float64{{40, -10}, {55, 20}, {45, 0}, {50, 10}}},
+		{"antimeridian", Box{MinLat: -20, MinLon: 179.5, MaxLat: -15, MaxLon: -179.5},
+			[][2]float64{{-17, 179.9}, {-17, -179.9}, {-20, 180}, {-15, -180}, {-18, 179.5}, {-18, -179.5}}},
+		{"pole", RadiusBox(89.9, 0, 50),
+			[][2]float64{{90, 0}, {89.8, 170}, {89.8, -170}, {89.6, 90}}},
+		{"whole world", Box{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180},
+			[][2]float64{{-90, -180}, {90, 180}, {0, 0}}},
+	}
+
+	for _, c := range cases {
+		cover := Cover(c.box)
+		if len(cover) > 2*maxCells {
+			t.Errorf("%s: Cover(%+v) returned %d cells", c.name, c.box, len(cover))
+		}
+
+		for _, p := range c.points {
+			if !c.box.Contains(p[0], p[1]) {
+				t.Errorf("%s: %+v doesn't contain %v", c.name, c.box, p)
+				continue
+			}
+
+			hash := Encode(p[0], p[1], Precision)
+			var found bool
+			for _, prefix := range cover {
+				if strings.HasPrefix(hash, prefix) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("%s: %v (%s) not covered by %v", c.name, p, hash, cover)
+			}
+		}
+	}
+}
+
+func TestRadiusBox(t *testing.T) {
+	// circle including north pole covers every longitude
+	b := RadiusBox(89.9, 0, 50)
+	if b.MaxLat != 90 || b.MinLon != -180 || b.MaxLon != 180 {
+		t.Errorf("RadiusBox near pole = %+v, want every longitude up to 90", b)
+	}
+
+	// circle crossing antimeridian wraps longitudes
+	b = RadiusBox(-17, 179.9, 50)
+	if b.MinLon <= b.MaxLon || !b.Contains(-17, -179.9) || !b.Contains(-17, 179.5) {
+		t.Errorf("RadiusBox across antimeridian = %+v, want MinLon > MaxLon", b)
+	}
+}
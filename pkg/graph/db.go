@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// FromDB writes the graph of wiki stored in page, page_nature and article_reference tables
+func FromDB(db *sql.DB, wiki string, w Writer) error {
+	query := `SELECT p.page_id, p.title, COALESCE(n.nature, 0) FROM page p LEFT JOIN page_nature n ON n.wiki = p.wiki AND n.page_id = p.page_id WHERE p.wiki = $1 ORDER BY p.page_id`
+	rows, err := db.Query(query, wiki)
+	if err != nil {
+		return fmt.Errorf("SELECT page : %s", err)
+	}
+
+	var nodes int
+	for rows.Next() {
+		n := &Node{}
+		err = rows.Scan(&n.ID, &n.Title, &n.Nature)
+		if err == nil {
+			err = w.WriteNode(n)
+		}
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		nodes++
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("SELECT page : %s", err)
+	}
+	fmt.Printf("Wrote %d nodes\n", nodes)
+
+	query = `SELECT page_id, refered_page, occurrence, reference_index FROM article_reference WHERE wiki = $1`
+	rows, err = db.Query(query, wiki)
+	if err != nil {
+		return fmt.Errorf("SELECT article_reference : %s", err)
+	}
+	defer rows.Close()
+
+	var edges int
+	for rows.Next() {
+		e := &Edge{}
+		err = rows.Scan(&e.Source, &e.Target, &e.Occurrence, &e.Index)
+		if err == nil {
+			err = w.WriteEdge(e)
+		}
+		if err != nil {
+			return err
+		}
+		edges++
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("SELECT article_reference : %s", err)
+	}
+	fmt.Printf("Wrote %d edges\n", edges)
+
+	return nil
+}
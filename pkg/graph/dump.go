@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/classifier"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// DumpGraph builds the graph directly from dumps, without database.
+//
+// Nodes are written while pages are streamed. References can target pages not read yet,
+// so they are spooled to a temporary file and resolved against page titles on Close.
+type DumpGraph struct {
+	w        Writer
+	trailing []string
+	c        *classifier.Classifier
+	ids      map[string]int
+	spool    *os.File
+	sw       *bufio.Writer
+}
+
+// NewDumpGraph creates a DumpGraph of wiki pages, classified with c. References found in wiki trailing sections,
+// such as See also, are skipped.
+func NewDumpGraph(w Writer, tmpfolder string, wiki string, c *classifier.Classifier) (*DumpGraph, error) {
+	f, err := os.CreateTemp(tmpfolder, "graph-edges-*.tsv")
+	if err != nil {
+		return nil, err
+	}
+
+	g := &DumpGraph{
+		w:        w,
+		trailing: parser.TrailingSectionTitles(wiki),
+		c:        c,
+		ids:      make(map[string]int),
+		spool:    f,
+		sw:       bufio.NewWriter(f),
+	}
+	return g, nil
+}
+
+// AddPages writes a node for every article of pagech and spools its references. Links are classified with dump
+// namespaces ns.
+func (g *DumpGraph) AddPages(pagech chan reader.Page, ns *parser.Namespaces) error {
+	for p := range pagech {
+		err := g.addPage(&p, ns)
+		if err != nil {
+			for range pagech {
+			}
+			return fmt.Errorf("%s (%d): %s", p.Title, p.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *DumpGraph) addPage(p *reader.Page, ns *parser.Namespaces) error {
+	if parser.IsMeta(p) {
+		return nil
+	}
+
+	nodes := parser.Parse(p.Text)
+	nature, _ := g.c.Classify(p, nodes, ns)
+
+	err := g.w.WriteNode(&Node{ID: p.ID, Title: p.Title, Nature: nature})
+	if err != nil {
+		return err
+	}
+	g.ids[p.Title] = p.ID
+
+	for _, ref := range parser.References(nodes, g.trailing, ns) {
+		if ref.Trailing {
+			continue
+		}
+		_, err = fmt.Fprintf(g.sw, "%d\t%d\t%d\t%s\n", p.ID, ref.Occurence, ref.Index, ref.Title)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close resolves spooled references, writes edges and closes Writer
+func (g *DumpGraph) Close() error {
+	defer os.Remove(g.spool.Name())
+	defer g.spool.Close()
+
+	err := g.sw.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = g.spool.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+
+	var edges, unresolved int
+	scanner := bufio.NewScanner(g.spool)
+	for scanner.Scan() {
+		t := strings.SplitN(scanner.Text(), "\t", 4)
+		if len(t) != 4 {
+			continue
+		}
+
+		target, ok := g.ids[t[3]]
+		if !ok {
+			unresolved++
+			continue
+		}
+
+		e := &Edge{Target: target}
+		e.Source, _ = strconv.Atoi(t[0])
+		e.Occurrence, _ = strconv.Atoi(t[1])
+		e.Index, _ = strconv.Atoi(t[2])
+		err = g.w.WriteEdge(e)
+		if err != nil {
+			return err
+		}
+		edges++
+	}
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d nodes, %d edges (%d unresolved references)\n", len(g.ids), edges, unresolved)
+	return g.w.Close()
+}
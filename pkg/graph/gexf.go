@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+)
+
+const gexfHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <graph mode="static" defaultedgetype="directed">
+    <attributes class="node">
+      <attribute id="nature" title="nature" type="string"/>
+    </attributes>
+    <attributes class="edge">
+      <attribute id="occurrence" title="occurrence" type="integer"/>
+      <attribute id="reference_index" title="reference_index" type="integer"/>
+    </attributes>
+    <nodes>
+`
+
+// gexfWriter switches from <nodes> to <edges> section on first edge, GEXF not allowing them mixed
+type gexfWriter struct {
+	f *os.File
+	w *bufio.Writer
+
+	edges int
+}
+
+func newGEXFWriter(folder string) (*gexfWriter, error) {
+	f, err := os.Create(path.Join(folder, "graph.gexf"))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &gexfWriter{f: f, w: bufio.NewWriter(f)}
+	_, err = w.w.WriteString(gexfHeader)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *gexfWriter) WriteNode(n *Node) error {
+	if w.edges > 0 {
+		return fmt.Errorf("gexf: node %d written after edges", n.ID)
+	}
+
+	_, err := fmt.Fprintf(w.w, "      <node id=\"%d\" label=\"%s\"><attvalues><attvalue for=\"nature\" value=\"%s\"/></attvalues></node>\n", n.ID, escape(n.Title), n.Nature)
+	return err
+}
+
+func (w *gexfWriter) WriteEdge(e *Edge) error {
+	if w.edges == 0 {
+		_, err := w.w.WriteString("    </nodes>\n    <edges>\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w.w, "      <edge id=\"%d\" source=\"%d\" target=\"%d\" weight=\"%d\"><attvalues><attvalue for=\"occurrence\" value=\"%d\"/><attvalue for=\"reference_index\" value=\"%d\"/></attvalues></edge>\n", w.edges, e.Source, e.Target, e.Occurrence, e.Occurrence, e.Index)
+	w.edges++
+	return err
+}
+
+func (w *gexfWriter) Close() error {
+	footer := "    </edges>\n  </graph>\n</gexf>\n"
+	if w.edges == 0 {
+		footer = "    </nodes>\n    <edges>\n" + footer
+	}
+
+	_, err := w.w.WriteString(footer)
+	if err == nil {
+		err = w.w.Flush()
+	}
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+
+	return w.f.Close()
+}
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+)
+
+// Node is a page of the article_reference graph
+type Node struct {
+	ID     int
+	Title  string
+	Nature parser.Nature
+}
+
+// Edge is a reference from Source page to Target page
+type Edge struct {
+	Source     int
+	Target     int
+	Occurrence int
+	Index      int
+}
+
+// Writer writes a graph in a given format. All nodes must be written before the first edge.
+type Writer interface {
+	WriteNode(n *Node) error
+	WriteEdge(e *Edge) error
+	Close() error
+}
+
+// NewWriter creates a Writer for format ('graphml', 'gexf' or 'neo4j') writing files into folder
+func NewWriter(format string, folder string) (Writer, error) {
+	err := os.MkdirAll(folder, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "graphml":
+		return newGraphMLWriter(folder)
+	case "gexf":
+		return newGEXFWriter(folder)
+	case "neo4j":
+		return newNeo4jWriter(folder)
+	default:
+		return nil, fmt.Errorf("unknown graph format '%s'", format)
+	}
+}
+
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+)
+
+const graphMLHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">
+  <key id="title" for="node" attr.name="title" attr.type="string"/>
+  <key id="nature" for="node" attr.name="nature" attr.type="string"/>
+  <key id="occurrence" for="edge" attr.name="occurrence" attr.type="int"/>
+  <key id="reference_index" for="edge" attr.name="reference_index" attr.type="int"/>
+  <graph id="wikipedia" edgedefault="directed">
+`
+
+const graphMLFooter = `  </graph>
+</graphml>
+`
+
+type graphMLWriter struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+func newGraphMLWriter(folder string) (*graphMLWriter, error) {
+	f, err := os.Create(path.Join(folder, "graph.graphml"))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &graphMLWriter{f: f, w: bufio.NewWriter(f)}
+	_, err = w.w.WriteString(graphMLHeader)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *graphMLWriter) WriteNode(n *Node) error {
+	_, err := fmt.Fprintf(w.w, "    <node id=\"%d\"><data key=\"title\">%s</data><data key=\"nature\">%s</data></node>\n", n.ID, escape(n.Title), n.Nature)
+	return err
+}
+
+func (w *graphMLWriter) WriteEdge(e *Edge) error {
+	_, err := fmt.Fprintf(w.w, "    <edge source=\"%d\" target=\"%d\"><data key=\"occurrence\">%d</data><data key=\"reference_index\">%d</data></edge>\n", e.Source, e.Target, e.Occurrence, e.Index)
+	return err
+}
+
+func (w *graphMLWriter) Close() error {
+	_, err := w.w.WriteString(graphMLFooter)
+	if err == nil {
+		err = w.w.Flush()
+	}
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+
+	return w.f.Close()
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"encoding/csv"
+	"os"
+	"path"
+	"strconv"
+)
+
+// neo4jWriter writes nodes.csv and references.csv with headers expected by neo4j-admin import:
+//
+//	neo4j-admin database import full --nodes=nodes.csv --relationships=references.csv
+type neo4jWriter struct {
+	nodesf *os.File
+	nodes  *csv.Writer
+	edgesf *os.File
+	edges  *csv.Writer
+}
+
+func newNeo4jWriter(folder string) (*neo4jWriter, error) {
+	w := &neo4jWriter{}
+	var err error
+
+	w.nodesf, err = os.Create(path.Join(folder, "nodes.csv"))
+	if err != nil {
+		return nil, err
+	}
+	w.nodes = csv.NewWriter(w.nodesf)
+
+	w.edgesf, err = os.Create(path.Join(folder, "references.csv"))
+	if err != nil {
+		w.nodesf.Close()
+		return nil, err
+	}
+	w.edges = csv.NewWriter(w.edgesf)
+
+	err = w.nodes.Write([]string{"page_id:ID", "title", "nature", ":LABEL"})
+	if err == nil {
+		err = w.edges.Write([]string{":START_ID", ":END_ID", "occurrence:int", "reference_index:int", ":TYPE"})
+	}
+	if err != nil {
+		w.nodesf.Close()
+		w.edgesf.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *neo4jWriter) WriteNode(n *Node) error {
+	return w.nodes.Write([]string{strconv.Itoa(n.ID), n.Title, n.Nature.String(), "Page"})
+}
+
+func (w *neo4jWriter) WriteEdge(e *Edge) error {
+	return w.edges.Write([]string{strconv.Itoa(e.Source), strconv.Itoa(e.Target), strconv.Itoa(e.Occurrence), strconv.Itoa(e.Index), "REFERS_TO"})
+}
+
+func (w *neo4jWriter) Close() error {
+	var firstErr error
+
+	for _, c := range []struct {
+		w *csv.Writer
+		f *os.File
+	}{{w.nodes, w.nodesf}, {w.edges, w.edgesf}} {
+		c.w.Flush()
+		err := c.w.Error()
+		if err == nil {
+			err = c.f.Close()
+		} else {
+			c.f.Close()
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/exporter"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// Export streams dumps into exporter files instead of a database.
+func Export(e *exporter.Exporter, basefolder string, tightmode bool, interactive bool, language string) error {
+
+	err := Walk(basefolder, tightmode, interactive, language, func(dumpName string, si *reader.SiteInfo, pagech chan reader.Page) error {
+		fmt.Printf("Exporting dump %s\n", dumpName)
+		begin := time.Now()
+
+		err := e.Export(dumpName, parser.NewNamespaces(si), pagech)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Finished %s done (%s)\n", dumpName, time.Since(begin))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.Close()
+}
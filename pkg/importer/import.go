@@ -3,41 +3,23 @@ package importer
 import (
 	"database/sql"
 	"fmt"
-	"os"
-	"path"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/proullon/wikipedia-to-cockroachdb/pkg/downloader"
 	"github.com/proullon/wikipedia-to-cockroachdb/pkg/inserter"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
 	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
 )
 
-func Import(db *sql.DB, basefolder string, parallelisationFactor int, tightmode bool, withPageContent bool, withPageReferences bool, interactive bool, language string) error {
+// Import inserts selected dumps of language in database. opts select which tables are populated besides page.
+func Import(db *sql.DB, basefolder string, parallelisationFactor int, tightmode bool, interactive bool, language string, opts ...inserter.Option) error {
 
-	urls, err := downloader.ListArticleDumps(interactive, language)
-	if err != nil {
-		return err
-	}
-	filech, err := downloader.DownloadDumps(basefolder, language, urls)
-	if err != nil {
-		return err
-	}
-
-	for dumpName := range filech {
-		p := path.Join(basefolder, dumpName)
-		fmt.Printf("Opening %s\n", p)
+	err := Walk(basefolder, tightmode, interactive, language, func(dumpName string, si *reader.SiteInfo, pagech chan reader.Page) error {
+		fmt.Printf("Inserting dump %s\n", dumpName)
 		begin := time.Now()
-
-		si, pagech, err := reader.StreamDumpPages(p)
-		if err != nil {
-			return err
-		}
-
-		fmt.Printf("Inserting dump %s: %+v (opening took %s)\n", dumpName, si, time.Since(begin))
-		begin = time.Now()
-		i := inserter.New(db, parallelisationFactor, withPageContent, withPageReferences)
+		// namespaces are known from dump siteinfo, options given by caller come first so they can't override them
+		i := inserter.New(db, parallelisationFactor, language, append(opts, inserter.WithNamespaces(parser.NewNamespaces(si)))...)
 
 		errch := i.ImportStream(pagech)
 		var errc int
@@ -47,29 +29,21 @@ func Import(db *sql.DB, basefolder string, parallelisationFactor int, tightmode
 		}
 
 		fmt.Printf("Finished %s done (%s) (%d errors)\n", dumpName, time.Since(begin), errc)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		if tightmode {
-			err = removeDump(path.Join(basefolder, dumpName))
-			if err != nil {
-				log.Errorf("cannot remove file %s: %s", dumpName, err)
-			}
-			err = removeDumpArchive(path.Join(basefolder, dumpName))
-			if err != nil {
-				log.Errorf("cannot remove archive file %s: %s", dumpName, err)
-			}
-		}
+	// interlanguage links stored by imports of other wikis can now point to imported pages. It runs whatever opts,
+	// since those links don't depend on this import storing its own.
+	n, err := inserter.ResolveLangLinks(db, language)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		fmt.Printf("Resolved %d interlanguage links to %s\n", n, language)
 	}
 
 	return nil
 }
-
-func removeDump(filepath string) error {
-	fmt.Printf("Removing %s\n", filepath)
-	return os.Remove(filepath)
-}
-
-func removeDumpArchive(filepath string) error {
-	filepath += ".bz2"
-	fmt.Printf("Removing %s\n", filepath)
-	return os.Remove(filepath)
-}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/downloader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// WalkFunc is called for each dump with its page stream, it must consume pagech until closed.
+type WalkFunc func(dumpName string, si *reader.SiteInfo, pagech chan reader.Page) error
+
+// Walk lists, downloads and streams every selected dump of given language to fn.
+// In tight mode, dump is removed from disk once fn returns.
+func Walk(basefolder string, tightmode bool, interactive bool, language string, fn WalkFunc) error {
+
+	urls, err := downloader.ListArticleDumps(interactive, language)
+	if err != nil {
+		return err
+	}
+	filech, err := downloader.DownloadDumps(basefolder, language, urls)
+	if err != nil {
+		return err
+	}
+
+	for dumpName := range filech {
+		p := path.Join(basefolder, dumpName)
+		fmt.Printf("Opening %s\n", p)
+		begin := time.Now()
+
+		si, pagech, err := reader.StreamDumpPages(p)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Opened %s: %+v (took %s)\n", dumpName, si, time.Since(begin))
+
+		err = fn(dumpName, si, pagech)
+		if err != nil {
+			return err
+		}
+
+		if tightmode {
+			removeDumpFiles(basefolder, dumpName)
+		}
+	}
+
+	return nil
+}
+
+func removeDumpFiles(basefolder string, dumpName string) {
+	err := removeDump(path.Join(basefolder, dumpName))
+	if err != nil {
+		log.Errorf("cannot remove file %s: %s", dumpName, err)
+	}
+	err = removeDumpArchive(path.Join(basefolder, dumpName))
+	if err != nil {
+		log.Errorf("cannot remove archive file %s: %s", dumpName, err)
+	}
+}
+
+func removeDump(filepath string) error {
+	fmt.Printf("Removing %s\n", filepath)
+	return os.Remove(filepath)
+}
+
+func removeDumpArchive(filepath string) error {
+	filepath += ".bz2"
+	fmt.Printf("Removing %s\n", filepath)
+	return os.Remove(filepath)
+}
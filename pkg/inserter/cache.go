@@ -2,11 +2,11 @@ package inserter
 
 import (
 	"database/sql"
-	"strings"
 	"sync"
 )
 
 // we could use redis, but a good ol' map is good enough...
+// PageIndex keys are wiki and normalized title, see cacheKey
 var (
 	PageIndex map[string]int
 	indexm    sync.Mutex
@@ -24,34 +24,40 @@ func Cached() int {
 	return n
 }
 
-func Cache(title string, id int) {
+func Cache(wiki string, title string, id int) {
 	indexm.Lock()
-	PageIndex[title] = id
+	PageIndex[cacheKey(wiki, title)] = id
 	indexm.Unlock()
 }
 
-func GetPage(db *sql.DB, title string) (int, error) {
-	title = strings.ToLower(title)
+// GetPage returns id of page titled title in wiki. Lookup is exact, title being normalized as page titles are, see
+// title.Normalize: 'MIT' and 'Mit' are distinct pages.
+func GetPage(db *sql.DB, wiki string, title string) (int, error) {
+	key := cacheKey(wiki, title)
 
 	indexm.Lock()
 	if PageIndex == nil {
 		PageIndex = make(map[string]int)
 	}
-	id, ok := PageIndex[title]
+	id, ok := PageIndex[key]
 	indexm.Unlock()
 	if ok {
 		hit++
 		return id, nil
 	}
 
-	query := `SELECT page_id FROM page WHERE lower_title = $1`
-	err := db.QueryRow(query, title).Scan(&id)
+	query := `SELECT page_id FROM page WHERE wiki = $1 AND title = $2`
+	err := db.QueryRow(query, wiki, title).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 
 	indexm.Lock()
-	PageIndex[title] = id
+	PageIndex[key] = id
 	indexm.Unlock()
 	return id, nil
 }
+
+func cacheKey(wiki string, title string) string {
+	return wiki + ":" + title
+}
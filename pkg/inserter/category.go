@@ -0,0 +1,101 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// insertPageCategories stores article category memberships
+func insertPageCategories(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) error {
+	categories := parser.Categories(nodes, ns)
+
+	query := `DELETE FROM page_category WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_category : %s", p.Title, p.ID, err)
+	}
+
+	if len(categories) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for _, c := range categories {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, c.Name, title.Key(c.Name), c.SortKey)
+	}
+
+	query = `INSERT INTO page_category (wiki, page_id, category, lower_category, sort_key) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_category : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
+
+// insertCategory stores a Category namespace page in category, and its own memberships as subcategory edges
+func (i *Inserter) insertCategory(p reader.Page) error {
+	var err error
+
+	name := parser.CategoryTitle(&p)
+	categories := parser.Categories(parser.Parse(p.Text), i.namespaces)
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): Begin : %s", p.Title, p.ID, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	query := `DELETE FROM category WHERE wiki = $1 AND category_id = $2`
+	_, err = tx.Exec(query, i.wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE category : %s", p.Title, p.ID, err)
+	}
+
+	query = `INSERT INTO category (wiki, category_id, title, lower_title) VALUES ($1, $2, $3, $4)`
+	_, err = tx.Exec(query, i.wiki, p.ID, name, title.Key(name))
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT category : %s", p.Title, p.ID, err)
+	}
+
+	query = `DELETE FROM subcategory WHERE wiki = $1 AND category_id = $2`
+	_, err = tx.Exec(query, i.wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE subcategory : %s", p.Title, p.ID, err)
+	}
+
+	var values []string
+	args := []interface{}{i.wiki, p.ID, name, title.Key(name)}
+	for _, c := range categories {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $3, $4, $%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, c.Name, title.Key(c.Name), c.SortKey)
+	}
+
+	if len(values) > 0 {
+		query = `INSERT INTO subcategory (wiki, category_id, title, lower_title, parent, lower_parent, sort_key) VALUES ` + strings.Join(values, ", ")
+		_, err = tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("Inserting %s (%d): INSERT subcategory : %s", p.Title, p.ID, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): COMMIT : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
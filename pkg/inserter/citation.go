@@ -0,0 +1,47 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// insertPageCitations stores citations of page, empty fields being NULL
+func insertPageCitations(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) error {
+	citations := parser.Citations(nodes, ns)
+
+	query := `DELETE FROM page_citation WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_citation : %s", p.Title, p.ID, err)
+	}
+
+	if len(citations) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for ordinal, c := range citations {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11))
+		args = append(args, ordinal, c.Section, c.Template, nullString(c.Title), nullString(c.Author), nullString(c.Date),
+			nullString(c.DOI), nullString(c.ISBN), nullString(c.URL), nullString(c.Publisher), nullString(c.AccessDate))
+	}
+
+	query = `INSERT INTO page_citation (wiki, page_id, ordinal, section_ordinal, template, title, author, publication_date, doi, isbn, url, publisher, access_date) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_citation : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
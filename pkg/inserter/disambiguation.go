@@ -0,0 +1,58 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// insertDisambiguationCandidates stores articles linked from page if it is a disambiguation page
+func insertDisambiguationCandidates(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, disambiguation bool) error {
+	query := `DELETE FROM disambiguation_candidate WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE disambiguation_candidate : %s", p.Title, p.ID, err)
+	}
+
+	if !disambiguation {
+		return nil
+	}
+
+	candidates := parser.DisambiguationCandidates(nodes, ns)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for ordinal, candidate := range candidates {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, ordinal, candidate, title.Key(candidate))
+	}
+
+	query = `INSERT INTO disambiguation_candidate (wiki, page_id, ordinal, title, lower_title) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT disambiguation_candidate : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
+
+// ExcludeDisambiguationReferences deletes references of wiki pointing to disambiguation pages and returns how many
+// were deleted
+func ExcludeDisambiguationReferences(db *sql.DB, wiki string) (int64, error) {
+	query := `DELETE FROM article_reference WHERE wiki = $1
+		AND refered_page IN (SELECT page_id FROM page WHERE wiki = $1 AND is_disambiguation)`
+	res, err := db.Exec(query, wiki)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,41 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// insertExternalLinks stores external links of page, each url once
+func insertExternalLinks(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) error {
+	links := parser.ExternalLinks(nodes, ns)
+
+	query := `DELETE FROM external_link WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE external_link : %s", p.Title, p.ID, err)
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for _, l := range links {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, l.URL, l.Domain, l.InCitation)
+	}
+
+	query = `INSERT INTO external_link (wiki, page_id, url, domain, in_citation) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT external_link : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/geo"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// insertPageGeo stores coordinates of page with their geohash
+func insertPageGeo(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces) error {
+	coordinates := parser.Coordinates(nodes, ns, wiki)
+
+	query := `DELETE FROM page_geo WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_geo : %s", p.Title, p.ID, err)
+	}
+
+	if len(coordinates) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for ordinal, c := range coordinates {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+		args = append(args, ordinal, c.Lat, c.Lon, geo.Encode(c.Lat, c.Lon, geo.Precision), c.Source, c.Primary)
+	}
+
+	query = `INSERT INTO page_geo (wiki, page_id, ordinal, lat, lon, geohash, source, is_primary) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_geo : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// insertPageInfoboxes stores parameters of page first infobox, or of all of them if all is set.
+// Values starting with a number also get it parsed in numeric_value.
+func insertPageInfoboxes(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, templates []string, all bool) error {
+	infoboxes := parser.Infoboxes(nodes, templates, ns)
+	if !all && len(infoboxes) > 1 {
+		infoboxes = infoboxes[:1]
+	}
+
+	query := `DELETE FROM page_infobox WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_infobox : %s", p.Title, p.ID, err)
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for ordinal, infobox := range infoboxes {
+		for _, param := range infobox.Params {
+			var numeric sql.NullFloat64
+			if f, ok := parser.ParseNumber(param.Value, wiki); ok {
+				numeric.Float64, numeric.Valid = f, true
+			}
+
+			n := len(args)
+			values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+			args = append(args, ordinal, infobox.Type, param.Key, param.Value, numeric)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query = `INSERT INTO page_infobox (wiki, page_id, ordinal, infobox_type, key, value, numeric_value) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_infobox : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
@@ -2,35 +2,72 @@ package inserter
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/proullon/workerpool"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/classifier"
 	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
 	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
 )
 
 type Inserter struct {
 	errch chan error
 
-	db                   *sql.DB
-	insertPageContent    bool
-	insertPageReferences bool
-	done                 int
-	errors               int
+	db                      *sql.DB
+	wiki                    string
+	insertPageContent       bool
+	insertPlaintext         bool
+	skipWikitext            bool
+	plaintextTemplates      []string
+	insertPageReferences    bool
+	trailingSections        []string
+	trailingReferences      bool
+	referenceMentions       bool
+	insertPageLangLinks     bool
+	insertPageSections      bool
+	insertPageInfoboxes     bool
+	allInfoboxes            bool
+	insertPageNature        bool
+	classifier              *classifier.Classifier
+	insertCategories        bool
+	insertPageTemplates     bool
+	templateParams          bool
+	namespaces              *parser.Namespaces
+	insertExternalLinks     bool
+	insertPageCitations     bool
+	insertPageGeo           bool
+	insertDisambiguation    bool
+	disambiguationTemplates []string
+	excludeDisambiguations  bool
+	insertPageStats         bool
+	done                    int
+	errors                  int
 
 	wp *workerpool.WorkerPool
 }
 
-func New(db *sql.DB, n int, insertPageContent bool, insertPageReferences bool) *Inserter {
+// New creates an Inserter for given wiki (language code), every row inserted being scoped by it.
+// Only page table is populated unless options are given.
+func New(db *sql.DB, n int, wiki string, opts ...Option) *Inserter {
 	i := &Inserter{
-		errch:                make(chan error),
-		db:                   db,
-		insertPageContent:    insertPageContent,
-		insertPageReferences: insertPageReferences,
+		errch:                   make(chan error),
+		db:                      db,
+		wiki:                    wiki,
+		plaintextTemplates:      parser.DefaultPlaintextTemplates,
+		trailingSections:        parser.TrailingSectionTitles(wiki),
+		namespaces:              parser.DefaultNamespaces,
+		disambiguationTemplates: parser.DisambiguationTemplateNames(wiki),
+	}
+
+	for _, opt := range opts {
+		opt(i)
 	}
 
 	i.wp, _ = workerpool.New(i.Insert,
@@ -50,6 +87,16 @@ func (i *Inserter) ImportStream(pagech chan reader.Page) chan error {
 		}
 		log.Infof("ImportStream: Done feeding WorkerPool")
 		i.wp.Wait()
+
+		// errch is closed once WorkerPool is stopped, so exclusion error is sent before
+		if i.excludeDisambiguations {
+			n, err := ExcludeDisambiguationReferences(i.db, i.wiki)
+			if err != nil {
+				i.errch <- fmt.Errorf("Excluding references to disambiguation pages: %s", err)
+			} else {
+				fmt.Printf("Excluded %d references to disambiguation pages\n", n)
+			}
+		}
 		i.wp.Stop()
 
 		v := i.wp.VelocityValues()
@@ -104,18 +151,14 @@ func (i *Inserter) Insert(payload interface{}) (interface{}, error) {
 func (i *Inserter) insert(p reader.Page) error {
 	var err error
 
-	// TODO: should be in config
-	var ignoredPrefixes = []string{
-		"wikipedia", "template", "project", "portal", "category", "draft", "module",
-		"wikipédia", "modèle", "projet", "portail", "catégorie", "draft", "module",
+	if i.insertCategories && parser.IsCategoryPage(&p) {
+		return i.insertCategory(p)
 	}
 
 	// do not insert wikipedia meta page
-	for _, prefix := range ignoredPrefixes {
-		if strings.HasPrefix(strings.ToLower(p.Title), prefix+":") {
-			log.Infof("Ignoring %s", p.Title)
-			return nil
-		}
+	if parser.IsMeta(&p) {
+		log.Infof("Ignoring %s", p.Title)
+		return nil
 	}
 
 	tx, err := i.db.Begin()
@@ -128,27 +171,115 @@ func (i *Inserter) insert(p reader.Page) error {
 		}
 	}()
 
-	query := `DELETE FROM page WHERE page_id = $1`
-	_, err = tx.Exec(query, p.ID)
+	// page is parsed once for all extractors
+	var nodes []parser.Node
+	if i.insertPlaintext || i.insertPageReferences || i.insertPageLangLinks || i.insertPageSections || i.insertPageInfoboxes || i.insertPageNature || i.insertCategories || i.insertPageTemplates || i.insertExternalLinks || i.insertPageCitations || i.insertPageGeo || i.insertDisambiguation || i.insertPageStats {
+		nodes = parser.Parse(p.Text)
+	}
+
+	var disambiguation bool
+	if i.insertDisambiguation {
+		disambiguation = parser.IsDisambiguation(nodes, i.disambiguationTemplates, i.namespaces)
+	}
+
+	query := `DELETE FROM page WHERE wiki = $1 AND page_id = $2`
+	_, err = tx.Exec(query, i.wiki, p.ID)
 	if err != nil {
 		return fmt.Errorf("Inserting %s (%d): DELETE : %s", p.Title, p.ID, err)
 	}
 
-	query = `INSERT INTO page (page_id, title, lower_title) VALUES ($1, $2, $3)`
-	_, err = tx.Exec(query, p.ID, p.Title, strings.ToLower(p.Title))
+	query = `INSERT INTO page (wiki, page_id, title, lower_title, is_disambiguation) VALUES ($1, $2, $3, $4, $5)`
+	_, err = tx.Exec(query, i.wiki, p.ID, p.Title, title.Key(p.Title), disambiguation)
 	if err != nil {
 		return fmt.Errorf("Inserting %s (%d): INSERT page : %s", p.Title, p.ID, err)
 	}
 
 	if i.insertPageContent {
-		err = insertPageContent(tx, &p)
+		err = i.insertPageContentRow(tx, &p, nodes)
 		if err != nil {
 			return err
 		}
 	}
 
 	if i.insertPageReferences {
-		err = insertPageReferences(i.db, tx, &p)
+		err = insertPageReferences(i.db, tx, i.wiki, &p, nodes, i.namespaces, i.trailingSections, i.trailingReferences, i.referenceMentions)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageLangLinks {
+		err = insertPageLangLinks(i.db, tx, i.wiki, &p, nodes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageSections {
+		err = insertPageSections(tx, i.wiki, &p, nodes, i.namespaces, i.plaintextTemplates)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageInfoboxes {
+		err = insertPageInfoboxes(tx, i.wiki, &p, nodes, i.namespaces, i.plaintextTemplates, i.allInfoboxes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageNature {
+		err = insertPageNature(tx, i.wiki, &p, nodes, i.namespaces, i.classifier)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertCategories {
+		err = insertPageCategories(tx, i.wiki, &p, nodes, i.namespaces)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageTemplates {
+		err = insertPageTemplates(tx, i.wiki, &p, nodes, i.namespaces, i.templateParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertExternalLinks {
+		err = insertExternalLinks(tx, i.wiki, &p, nodes, i.namespaces)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageCitations {
+		err = insertPageCitations(tx, i.wiki, &p, nodes, i.namespaces)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageGeo {
+		err = insertPageGeo(tx, i.wiki, &p, nodes, i.namespaces)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertDisambiguation {
+		err = insertDisambiguationCandidates(tx, i.wiki, &p, nodes, i.namespaces, disambiguation)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.insertPageStats {
+		err = insertPageStats(tx, i.wiki, &p, nodes, i.namespaces, i.plaintextTemplates)
 		if err != nil {
 			return err
 		}
@@ -162,15 +293,24 @@ func (i *Inserter) insert(p reader.Page) error {
 	return nil
 }
 
-func insertPageContent(tx *sql.Tx, p *reader.Page) error {
-	query := `DELETE FROM page_content WHERE page_id = $1`
-	_, err := tx.Exec(query, p.ID)
+// insertPageContentRow stores page wikitext and/or plain text, depending on options
+func (i *Inserter) insertPageContentRow(tx *sql.Tx, p *reader.Page, nodes []parser.Node) error {
+	query := `DELETE FROM page_content WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, i.wiki, p.ID)
 	if err != nil {
 		return fmt.Errorf("Inserting %s (%d): DELETE : %s", p.Title, p.ID, err)
 	}
 
-	query = `INSERT INTO page_content (page_id, content) VALUES ($1, $2)`
-	_, err = tx.Exec(query, p.ID, p.Text)
+	var content, plaintext sql.NullString
+	if !i.skipWikitext {
+		content = sql.NullString{String: p.Text, Valid: true}
+	}
+	if i.insertPlaintext {
+		plaintext = sql.NullString{String: parser.Plaintext(nodes, i.plaintextTemplates, i.namespaces), Valid: true}
+	}
+
+	query = `INSERT INTO page_content (wiki, page_id, content, plaintext) VALUES ($1, $2, $3, $4)`
+	_, err = tx.Exec(query, i.wiki, p.ID, content, plaintext)
 	if err != nil {
 		return fmt.Errorf("Inserting %s (%d): INSERT page_content : %s", p.Title, p.ID, err)
 	}
@@ -178,12 +318,14 @@ func insertPageContent(tx *sql.Tx, p *reader.Page) error {
 	return nil
 }
 
-func insertPageReferences(db *sql.DB, tx *sql.Tx, p *reader.Page) error {
+// insertPageReferences stores references to imported articles. References from trailing sections are
+// skipped unless withTrailing is set.
+func insertPageReferences(db *sql.DB, tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, trailing []string, withTrailing bool, withMentions bool) error {
 
-	references := parser.PageReferences(p)
+	references := parser.References(nodes, trailing, ns)
 
-	query := `DELETE FROM article_reference WHERE page_id = $1`
-	_, err := tx.Exec(query, p.ID)
+	query := `DELETE FROM article_reference WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
 	if err != nil {
 		return err
 	}
@@ -194,13 +336,14 @@ func insertPageReferences(db *sql.DB, tx *sql.Tx, p *reader.Page) error {
 	}
 
 	var refID int
-	var first bool = true
-	query = `INSERT INTO article_reference (page_id, refered_page, occurrence, reference_index) VALUES `
 	existingReferences := make(map[int]*parser.Reference)
 	for _, ref := range references {
+		if ref.Trailing && !withTrailing {
+			continue
+		}
 		r := ref.Title
 
-		refID, err = GetPage(db, r)
+		refID, err = GetPage(db, wiki, r)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				continue
@@ -212,6 +355,14 @@ func insertPageReferences(db *sql.DB, tx *sql.Tx, p *reader.Page) error {
 		eref, ok := existingReferences[refID]
 		if ok {
 			eref.Occurence += ref.Occurence
+			if eref.Anchor == "" {
+				eref.Anchor = ref.Anchor
+			}
+			for surface, count := range ref.Surfaces {
+				eref.Surfaces[surface] += count
+			}
+			eref.Offsets = append(eref.Offsets, ref.Offsets...)
+			sort.Ints(eref.Offsets)
 		} else {
 			ref.ID = refID
 			existingReferences[refID] = ref
@@ -219,21 +370,36 @@ func insertPageReferences(db *sql.DB, tx *sql.Tx, p *reader.Page) error {
 
 	}
 
+	// all GetPage failed :(
+	if len(existingReferences) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki}
 	for _, ref := range existingReferences {
-		if first {
-			query = fmt.Sprintf("%s (%d, %d, %d, %d) ", query, p.ID, ref.ID, ref.Occurence, ref.Index)
-			first = false
-		} else {
-			query = fmt.Sprintf("%s, (%d, %d, %d, %d) ", query, p.ID, ref.ID, ref.Occurence, ref.Index)
+		var anchor, surfaces, offsets sql.NullString
+		if withMentions {
+			anchor = nullString(ref.Anchor)
+			b, err := json.Marshal(ref.Surfaces)
+			if err != nil {
+				return err
+			}
+			surfaces = nullString(string(b))
+			b, err = json.Marshal(parser.CharOffsets(p.Text, ref.Offsets))
+			if err != nil {
+				return err
+			}
+			offsets = nullString(string(b))
 		}
-	}
 
-	// first is true so all GetPage failed :(
-	if first {
-		return nil
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, %d, %d, %d, %d, %d, %t, $%d, $%d, $%d)", p.ID, ref.ID, ref.Occurence, ref.Index, ref.Section, ref.Trailing, n+1, n+2, n+3))
+		args = append(args, anchor, surfaces, offsets)
 	}
 
-	_, err = tx.Exec(query)
+	query = `INSERT INTO article_reference (wiki, page_id, refered_page, occurrence, reference_index, section_ordinal, trailing, anchor, surface_forms, char_offsets) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
 	if err != nil {
 		return fmt.Errorf("%s: || %s || %s", p.Title, query, err)
 	}
@@ -0,0 +1,121 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+const resolveBatchSize = 100000
+
+var (
+	importedWikis = make(map[string]bool)
+	importedm     sync.Mutex
+)
+
+func insertPageLangLinks(db *sql.DB, tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node) error {
+	links := parser.LangLinks(nodes)
+
+	query := `DELETE FROM page_langlink WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_langlink : %s", p.Title, p.ID, err)
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for _, l := range links {
+		var target sql.NullInt64
+
+		ok, err := isImported(db, l.Language)
+		if err != nil {
+			return err
+		}
+		if ok {
+			id, err := GetPage(db, l.Language, l.Title)
+			if err != nil && err != sql.ErrNoRows {
+				log.Errorf("Cannot find page '%s:%s': %s\n", l.Language, l.Title, err)
+			}
+			if err == nil {
+				target.Int64, target.Valid = int64(id), true
+			}
+		}
+
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, l.Language, l.Title, title.Key(l.Title), target)
+	}
+
+	query = `INSERT INTO page_langlink (wiki, page_id, target_wiki, target_title, lower_target_title, target_page_id) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_langlink : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
+
+// isImported returns true if at least one page of wiki is in database. Result is cached
+// so links toward wikis not imported don't cost a lookup each.
+func isImported(db *sql.DB, wiki string) (bool, error) {
+	importedm.Lock()
+	ok, cached := importedWikis[wiki]
+	importedm.Unlock()
+	if cached {
+		return ok, nil
+	}
+
+	var n int
+	query := `SELECT count(*) FROM (SELECT page_id FROM page WHERE wiki = $1 LIMIT 1) AS p`
+	err := db.QueryRow(query, wiki).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+
+	importedm.Lock()
+	importedWikis[wiki] = n > 0
+	importedm.Unlock()
+	return n > 0, nil
+}
+
+// ResolveLangLinks sets target page of interlanguage links pointing to wiki, for links
+// inserted before wiki was imported. It runs in batches of page_id ranges to keep transactions small.
+func ResolveLangLinks(db *sql.DB, wiki string) (int64, error) {
+	var max sql.NullInt64
+	query := `SELECT MAX(page_id) FROM page_langlink WHERE target_wiki = $1 AND target_page_id IS NULL`
+	err := db.QueryRow(query, wiki).Scan(&max)
+	if err != nil {
+		return 0, fmt.Errorf("SELECT page_langlink : %s", err)
+	}
+	if !max.Valid {
+		return 0, nil
+	}
+
+	query = `UPDATE page_langlink SET target_page_id = page.page_id FROM page
+		WHERE page_langlink.target_wiki = $1 AND page_langlink.target_page_id IS NULL
+		AND page_langlink.page_id >= $2 AND page_langlink.page_id < $3
+		AND page.wiki = page_langlink.target_wiki AND page.title = page_langlink.target_title`
+
+	var resolved int64
+	for from := int64(0); from <= max.Int64; from += resolveBatchSize {
+		res, err := db.Exec(query, wiki, from, from+resolveBatchSize)
+		if err != nil {
+			return resolved, fmt.Errorf("UPDATE page_langlink : %s", err)
+		}
+		n, _ := res.RowsAffected()
+		resolved += n
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,33 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/classifier"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+func insertPageNature(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, c *classifier.Classifier) error {
+	query := `DELETE FROM page_nature WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_nature : %s", p.Title, p.ID, err)
+	}
+
+	var infobox sql.NullString
+	if name := parser.FirstInfobox(nodes, ns); name != "" {
+		infobox.String, infobox.Valid = name, true
+	}
+
+	nature, _ := c.Classify(p, nodes, ns)
+
+	query = `INSERT INTO page_nature (wiki, page_id, nature, infobox) VALUES ($1, $2, $3, $4)`
+	_, err = tx.Exec(query, wiki, p.ID, int(nature), infobox)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_nature : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
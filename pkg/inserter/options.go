@@ -0,0 +1,172 @@
+package inserter
+
+import (
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/classifier"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+)
+
+// Option enables an optional part of page import
+type Option func(*Inserter)
+
+// WithPageContent inserts page wikitext in page_content
+func WithPageContent() Option {
+	return func(i *Inserter) {
+		i.insertPageContent = true
+	}
+}
+
+// WithPlaintext stores page rendered as plain text in page_content.plaintext.
+// Wikitext is stored too if withWikitext is set.
+func WithPlaintext(withWikitext bool) Option {
+	return func(i *Inserter) {
+		i.insertPlaintext = true
+		i.skipWikitext = !withWikitext
+	}
+}
+
+// WithPlaintextTemplates sets templates kept when rendering plain text, parser.DefaultPlaintextTemplates being used otherwise
+func WithPlaintextTemplates(templates []string) Option {
+	return func(i *Inserter) {
+		i.plaintextTemplates = templates
+	}
+}
+
+// WithPageReferences inserts references to other articles in article_reference
+func WithPageReferences() Option {
+	return func(i *Inserter) {
+		i.insertPageReferences = true
+	}
+}
+
+// WithTrailingSections sets titles of sections closing articles, parser.TrailingSectionTitles of wiki being used otherwise
+func WithTrailingSections(titles []string) Option {
+	return func(i *Inserter) {
+		i.trailingSections = titles
+	}
+}
+
+// WithTrailingReferences inserts references found in trailing sections too, flagged with article_reference.trailing
+func WithTrailingReferences() Option {
+	return func(i *Inserter) {
+		i.trailingReferences = true
+	}
+}
+
+// WithReferenceMentions stores, for each reference, first section anchor, displayed texts with their count and
+// character offsets of occurences
+func WithReferenceMentions() Option {
+	return func(i *Inserter) {
+		i.referenceMentions = true
+	}
+}
+
+// WithPageLangLinks inserts interlanguage links in page_langlink
+func WithPageLangLinks() Option {
+	return func(i *Inserter) {
+		i.insertPageLangLinks = true
+	}
+}
+
+// WithPageSections inserts page section tree in page_section, section text being rendered as plain text
+func WithPageSections() Option {
+	return func(i *Inserter) {
+		i.insertPageSections = true
+	}
+}
+
+// WithPageInfoboxes inserts parameters of page first infobox in page_infobox, or of every infobox if all is set
+func WithPageInfoboxes(all bool) Option {
+	return func(i *Inserter) {
+		i.insertPageInfoboxes = true
+		i.allInfoboxes = all
+	}
+}
+
+// WithPageNature classifies pages with c and inserts their nature and first infobox name in page_nature
+func WithPageNature(c *classifier.Classifier) Option {
+	return func(i *Inserter) {
+		i.insertPageNature = true
+		i.classifier = c
+	}
+}
+
+// WithCategories inserts article category memberships in page_category, and Category namespace pages in category,
+// their own memberships being stored as subcategory edges
+func WithCategories() Option {
+	return func(i *Inserter) {
+		i.insertCategories = true
+	}
+}
+
+// WithPageTemplates inserts template transclusions in page_template, with their parameters as JSON if withParams is set
+func WithPageTemplates(withParams bool) Option {
+	return func(i *Inserter) {
+		i.insertPageTemplates = true
+		i.templateParams = withParams
+	}
+}
+
+// WithNamespaces sets wiki namespaces, read from dump siteinfo. parser.DefaultNamespaces is used otherwise.
+func WithNamespaces(ns *parser.Namespaces) Option {
+	return func(i *Inserter) {
+		i.namespaces = ns
+	}
+}
+
+// WithExternalLinks inserts external links and cited urls in external_link, with their domain
+func WithExternalLinks() Option {
+	return func(i *Inserter) {
+		i.insertExternalLinks = true
+	}
+}
+
+// WithPageCitations inserts citation templates such as {{cite web}} in page_citation, with their title, author, DOI...
+func WithPageCitations() Option {
+	return func(i *Inserter) {
+		i.insertPageCitations = true
+	}
+}
+
+// WithPageGeo inserts coordinates of {{coord}} templates and infoboxes in page_geo, with their geohash
+func WithPageGeo() Option {
+	return func(i *Inserter) {
+		i.insertPageGeo = true
+	}
+}
+
+// WithPageStats inserts size metrics of pages in page_stats: byte size, word count, section, link, citation and
+// image counts, and revision timestamp
+func WithPageStats() Option {
+	return func(i *Inserter) {
+		i.insertPageStats = true
+	}
+}
+
+// WithDisambiguations sets page.is_disambiguation on pages transcluding a disambiguation template or containing
+// __DISAMBIG__, and inserts articles they link to in disambiguation_candidate
+func WithDisambiguations() Option {
+	return func(i *Inserter) {
+		i.insertDisambiguation = true
+	}
+}
+
+// WithDisambiguationTemplates sets templates marking a disambiguation page, instead of parser.DisambiguationTemplates
+// of wiki language
+func WithDisambiguationTemplates(templates []string) Option {
+	return func(i *Inserter) {
+		// caller slice is left untouched
+		i.disambiguationTemplates = make([]string, len(templates))
+		for j := range templates {
+			i.disambiguationTemplates[j] = parser.DefaultNamespaces.TemplateName(templates[j])
+		}
+	}
+}
+
+// WithoutDisambiguationReferences removes references to disambiguation pages from article_reference once pages are
+// inserted. It implies WithDisambiguations.
+func WithoutDisambiguationReferences() Option {
+	return func(i *Inserter) {
+		i.insertDisambiguation = true
+		i.excludeDisambiguations = true
+	}
+}
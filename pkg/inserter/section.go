@@ -0,0 +1,36 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+func insertPageSections(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, templates []string) error {
+	sections := parser.Sections(nodes, ns)
+
+	query := `DELETE FROM page_section WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_section : %s", p.Title, p.ID, err)
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for _, s := range sections {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6))
+		args = append(args, s.Ordinal, s.Parent, s.Level, s.Title, s.Anchor, s.Text(templates, ns))
+	}
+
+	query = `INSERT INTO page_section (wiki, page_id, ordinal, parent_ordinal, level, title, anchor, content) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_section : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
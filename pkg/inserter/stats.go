@@ -0,0 +1,28 @@
+package inserter
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// insertPageStats stores size metrics of page, plain text being rendered keeping given templates
+func insertPageStats(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, templates []string) error {
+	s := parser.PageStats(p, nodes, ns, templates)
+
+	query := `DELETE FROM page_stats WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_stats : %s", p.Title, p.ID, err)
+	}
+
+	query = `INSERT INTO page_stats (wiki, page_id, byte_size, word_count, section_count, internal_link_count, external_link_count, citation_count, image_count, revision_timestamp) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err = tx.Exec(query, wiki, p.ID, s.Bytes, s.Words, s.Sections, s.InternalLinks, s.ExternalLinks, s.Citations, s.Images, nullString(p.Timestamp))
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_stats : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
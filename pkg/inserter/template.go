@@ -0,0 +1,55 @@
+package inserter
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// insertPageTemplates stores every template transclusion of page, with its parameters as JSON object if withParams is set
+func insertPageTemplates(tx *sql.Tx, wiki string, p *reader.Page, nodes []parser.Node, ns *parser.Namespaces, withParams bool) error {
+	templates := parser.Templates(nodes, ns)
+
+	query := `DELETE FROM page_template WHERE wiki = $1 AND page_id = $2`
+	_, err := tx.Exec(query, wiki, p.ID)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): DELETE page_template : %s", p.Title, p.ID, err)
+	}
+
+	if len(templates) == 0 {
+		return nil
+	}
+
+	var values []string
+	args := []interface{}{wiki, p.ID}
+	for ordinal, t := range templates {
+		var params sql.NullString
+		if withParams {
+			m := make(map[string]string)
+			for _, param := range t.Params {
+				m[param.Name] = param.Value
+			}
+			b, err := json.Marshal(m)
+			if err != nil {
+				return fmt.Errorf("Inserting %s (%d): %s params : %s", p.Title, p.ID, t.Name, err)
+			}
+			params.String, params.Valid = string(b), true
+		}
+
+		n := len(args)
+		values = append(values, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, ordinal, t.Name, strings.ToLower(t.Name), len(t.Params), params)
+	}
+
+	query = `INSERT INTO page_template (wiki, page_id, ordinal, template, lower_template, param_count, params) VALUES ` + strings.Join(values, ", ")
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("Inserting %s (%d): INSERT page_template : %s", p.Title, p.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package migration
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+// Migration is a versioned schema change, loaded from migrations/<dialect>/<version>_<name>.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Load returns migrations of dialect ('cockroachdb' or 'sqlite') sorted by version
+func Load(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := migrations.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unknown dialect '%s'", dialect)
+	}
+
+	var list []Migration
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		name := strings.TrimSuffix(e.Name(), ".sql")
+		t := strings.SplitN(name, "_", 2)
+		if len(t) != 2 {
+			return nil, fmt.Errorf("invalid migration name %s", e.Name())
+		}
+		version, err := strconv.Atoi(t[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration name %s: %s", e.Name(), err)
+		}
+
+		data, err := migrations.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, Migration{Version: version, Name: t[1], SQL: string(data)})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// Latest returns the schema version expected by this binary
+func Latest(dialect string) (int, error) {
+	list, err := Load(dialect)
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	return list[len(list)-1].Version, nil
+}
+
+// Version returns current schema version of database, 0 if no migration was ever applied
+func Version(db *sql.DB) (int, error) {
+	err := createVersionTable(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	query := `SELECT COALESCE(MAX(version), 0) FROM schema_version`
+	err = db.QueryRow(query).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("SELECT schema_version : %s", err)
+	}
+
+	return version, nil
+}
+
+// Check returns an error if database schema is not up to date
+func Check(db *sql.DB, dialect string) error {
+	latest, err := Latest(dialect)
+	if err != nil {
+		return err
+	}
+
+	version, err := Version(db)
+	if err != nil {
+		return err
+	}
+
+	if version < latest {
+		return fmt.Errorf("database schema is at version %d, expected %d. Run 'importerctl migrate'", version, latest)
+	}
+	if version > latest {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, latest)
+	}
+
+	return nil
+}
+
+// Migrate applies pending migrations in order and returns the number applied.
+//
+// On CockroachDB, statements are executed one by one outside of a transaction, CockroachDB not
+// allowing some schema changes in explicit transactions. Migrations must therefore be idempotent
+// (IF NOT EXISTS...) so a failed migration can be applied again.
+// SQLite handles DDL in transactions, so each migration is applied atomically.
+func Migrate(db *sql.DB, dialect string) (int, error) {
+	list, err := Load(dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := Version(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var applied int
+	for _, m := range list {
+		if m.Version <= version {
+			continue
+		}
+
+		fmt.Printf("Applying migration %d %s\n", m.Version, m.Name)
+		begin := time.Now()
+
+		if dialect == "sqlite" {
+			err = applyInTransaction(db, &m)
+		} else {
+			err = apply(db, &m)
+		}
+		if err != nil {
+			return applied, fmt.Errorf("migration %d %s: %s", m.Version, m.Name, err)
+		}
+
+		fmt.Printf("Applied migration %d %s (took %s)\n", m.Version, m.Name, time.Since(begin))
+		applied++
+	}
+
+	return applied, nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func apply(db execer, m *Migration) error {
+	for _, stmt := range statements(m.SQL) {
+		_, err := db.Exec(stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO schema_version (version, name, applied_at) VALUES ($1, $2, $3)`
+	_, err := db.Exec(query, m.Version, m.Name, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("INSERT schema_version : %s", err)
+	}
+
+	return nil
+}
+
+func applyInTransaction(db *sql.DB, m *Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = apply(tx, m)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createVersionTable(db *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_version (version INT PRIMARY KEY, name TEXT, applied_at TIMESTAMP)`
+	_, err := db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("CREATE schema_version : %s", err)
+	}
+
+	return nil
+}
+
+// statements splits a migration file on lines ending with ';'. Statements therefore can't hold a line ending with ';'
+// before their end, comments included, see migrations/README.md.
+func statements(s string) []string {
+	var stmts []string
+	var current []string
+
+	for _, line := range strings.Split(s, "\n") {
+		current = append(current, line)
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			stmt := strings.TrimSpace(strings.Join(current, "\n"))
+			stmts = append(stmts, stmt)
+			current = nil
+		}
+	}
+
+	return stmts
+}
@@ -0,0 +1,43 @@
+/*
+** Scope every table by wiki (language code: 'en', 'fr', ...) so several languages can be imported in the same database.
+**
+** Existing rows are assumed to come from the default 'en' language, default is dropped afterward so inserts
+** must always set wiki.
+*/
+ALTER TABLE page ADD COLUMN IF NOT EXISTS wiki STRING NOT NULL DEFAULT 'en';
+ALTER TABLE page_content ADD COLUMN IF NOT EXISTS wiki STRING NOT NULL DEFAULT 'en';
+ALTER TABLE article_reference ADD COLUMN IF NOT EXISTS wiki STRING NOT NULL DEFAULT 'en';
+ALTER TABLE page_nature ADD COLUMN IF NOT EXISTS wiki STRING NOT NULL DEFAULT 'en';
+
+ALTER TABLE page ALTER COLUMN wiki DROP DEFAULT;
+ALTER TABLE page_content ALTER COLUMN wiki DROP DEFAULT;
+ALTER TABLE article_reference ALTER COLUMN wiki DROP DEFAULT;
+ALTER TABLE page_nature ALTER COLUMN wiki DROP DEFAULT;
+
+/* page_content foreign key must be dropped before page primary key changes. Its name depends on CockroachDB version.
+*/
+ALTER TABLE page_content DROP CONSTRAINT IF EXISTS fk_page_id_ref_page;
+ALTER TABLE page_content DROP CONSTRAINT IF EXISTS page_content_page_id_fkey;
+
+/* ALTER PRIMARY KEY keeps a unique index on former primary key, which would forbid the same page_id in 2 wikis
+*/
+ALTER TABLE page ALTER PRIMARY KEY USING COLUMNS (wiki, page_id);
+DROP INDEX IF EXISTS page@page_page_id_key CASCADE;
+
+ALTER TABLE page_content ALTER PRIMARY KEY USING COLUMNS (wiki, page_id);
+DROP INDEX IF EXISTS page_content@page_content_page_id_key CASCADE;
+ALTER TABLE page_content ADD CONSTRAINT IF NOT EXISTS page_content_page_fkey FOREIGN KEY (wiki, page_id) REFERENCES page (wiki, page_id) ON DELETE CASCADE;
+
+ALTER TABLE article_reference ALTER PRIMARY KEY USING COLUMNS (wiki, page_id, refered_page);
+DROP INDEX IF EXISTS article_reference@article_reference_page_id_refered_page_key CASCADE;
+
+ALTER TABLE page_nature ALTER PRIMARY KEY USING COLUMNS (wiki, page_id);
+DROP INDEX IF EXISTS page_nature@page_nature_page_id_key CASCADE;
+
+/* Title and incoming reference lookups are always done within a wiki
+*/
+CREATE INDEX IF NOT EXISTS page_wiki_title ON page (wiki, lower_title);
+DROP INDEX IF EXISTS page@page_title;
+
+CREATE INDEX IF NOT EXISTS incoming_wiki_reference ON article_reference (wiki, refered_page);
+DROP INDEX IF EXISTS article_reference@incoming_reference;
@@ -0,0 +1,15 @@
+/* page_langlink contains interlanguage links ([[fr:Paris]]). target_page_id is set once target wiki is imported.
+*/
+CREATE TABLE IF NOT EXISTS page_langlink (
+        wiki STRING NOT NULL,
+        page_id INT NOT NULL,
+        target_wiki STRING NOT NULL,
+        target_title TEXT,
+        lower_target_title TEXT,
+        target_page_id INT,
+        PRIMARY KEY (wiki, page_id, target_wiki)
+);
+
+/* incoming_langlink index allows finding language versions pointing to a given page
+*/
+CREATE INDEX IF NOT EXISTS incoming_langlink ON page_langlink (target_wiki, target_page_id);
@@ -0,0 +1,4 @@
+/* page_content.plaintext holds article rendered as plain text, without markup, templates, references nor tables.
+** content keeps wikitext. Either can be NULL depending on --page-content-format.
+*/
+ALTER TABLE page_content ADD COLUMN IF NOT EXISTS plaintext TEXT;
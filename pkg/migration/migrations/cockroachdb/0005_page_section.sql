@@ -0,0 +1,18 @@
+/* page_section contains page section tree. Ordinal 0 is the lead section, before first heading,
+** parent_ordinal being -1 for it. content is section text without subsections, rendered as plain text.
+*/
+CREATE TABLE IF NOT EXISTS page_section (
+        wiki STRING NOT NULL,
+        page_id INT NOT NULL,
+        ordinal INT NOT NULL,
+        parent_ordinal INT,
+        level INT,
+        title TEXT,
+        anchor TEXT,
+        content TEXT,
+        PRIMARY KEY (wiki, page_id, ordinal)
+);
+
+/* section_ordinal is the page_section ordinal where reference first appears
+*/
+ALTER TABLE article_reference ADD COLUMN IF NOT EXISTS section_ordinal INT;
@@ -0,0 +1,43 @@
+/* page_category contains article category memberships ([[Category:Capitals in Europe|Paris]]), sort_key
+** being link label or DEFAULTSORT value
+*/
+CREATE TABLE IF NOT EXISTS page_category (
+        wiki STRING NOT NULL,
+        page_id INT NOT NULL,
+        category TEXT,
+        lower_category TEXT NOT NULL,
+        sort_key TEXT,
+        PRIMARY KEY (wiki, page_id, lower_category)
+);
+
+/* category_member index allows listing pages of a category
+*/
+CREATE INDEX IF NOT EXISTS category_member ON page_category (wiki, lower_category);
+
+/* category contains Category namespace pages, title being without namespace
+*/
+CREATE TABLE IF NOT EXISTS category (
+        wiki STRING NOT NULL,
+        category_id INT NOT NULL,
+        title TEXT,
+        lower_title TEXT,
+        PRIMARY KEY (wiki, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS category_title ON category (wiki, lower_title);
+
+/* subcategory contains category tree edges, from category to its parent categories. Parents are referenced by title
+** since they may not have a page.
+*/
+CREATE TABLE IF NOT EXISTS subcategory (
+        wiki STRING NOT NULL,
+        category_id INT NOT NULL,
+        title TEXT,
+        lower_title TEXT,
+        parent TEXT,
+        lower_parent TEXT NOT NULL,
+        sort_key TEXT,
+        PRIMARY KEY (wiki, category_id, lower_parent)
+);
+
+CREATE INDEX IF NOT EXISTS subcategory_parent ON subcategory (wiki, lower_parent);
@@ -0,0 +1,19 @@
+/* page_template contains template transclusions of each page, ordinal being transclusion position in page.
+** template is the name without namespace prefix, first letter uppercased, params holds parameters as JSON object when
+** imported with --page-template-params. Parser functions and magic words are not stored:
+**
+** SELECT page.title FROM page_template JOIN page ON page.wiki = page_template.wiki AND page.page_id = page_template.page_id
+** WHERE page_template.wiki = 'en' AND lower_template = 'coord'
+*/
+CREATE TABLE IF NOT EXISTS page_template (
+        wiki STRING NOT NULL,
+        page_id INT NOT NULL,
+        ordinal INT NOT NULL,
+        template TEXT NOT NULL,
+        lower_template TEXT NOT NULL,
+        param_count INT NOT NULL,
+        params TEXT,
+        PRIMARY KEY (wiki, page_id, ordinal)
+);
+
+CREATE INDEX IF NOT EXISTS template_usage ON page_template (wiki, lower_template);
@@ -0,0 +1,14 @@
+/* external_link contains external links of each page, [http://target label] links and url parameter of citation
+** templates such as {{cite web}}. in_citation is set for links found in citation templates or references.
+** domain is the lowercased host without www. prefix, empty for links without host such as mailto:
+*/
+CREATE TABLE IF NOT EXISTS external_link (
+        wiki STRING NOT NULL,
+        page_id INT NOT NULL,
+        url TEXT NOT NULL,
+        domain TEXT NOT NULL,
+        in_citation BOOL NOT NULL DEFAULT false,
+        PRIMARY KEY (wiki, page_id, url)
+);
+
+CREATE INDEX IF NOT EXISTS external_link_domain ON external_link (wiki, domain);
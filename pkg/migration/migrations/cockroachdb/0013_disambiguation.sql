@@ -0,0 +1,24 @@
+/* is_disambiguation is set on pages transcluding a disambiguation template such as {{disambiguation}} or {{homonymie}},
+** or containing __DISAMBIG__ magic word, imported with --with-disambiguations
+*/
+ALTER TABLE page ADD COLUMN IF NOT EXISTS is_disambiguation BOOL NOT NULL DEFAULT false;
+
+/* disambiguation_candidate contains articles linked from disambiguation pages, ordinal being link position in page.
+** Candidates are joined to their page by exact title, normalized as page titles are, as they may be imported after
+** the disambiguation page:
+**
+** SELECT d.title, p.page_id, p.title FROM disambiguation_candidate c
+** JOIN page d ON d.wiki = c.wiki AND d.page_id = c.page_id
+** JOIN page p ON p.wiki = c.wiki AND p.title = c.title
+** WHERE c.wiki = 'en' AND d.lower_title = 'mercury'
+*/
+CREATE TABLE IF NOT EXISTS disambiguation_candidate (
+        wiki STRING NOT NULL,
+        page_id INT NOT NULL,
+        ordinal INT NOT NULL,
+        title TEXT NOT NULL,
+        lower_title TEXT NOT NULL,
+        PRIMARY KEY (wiki, page_id, ordinal)
+);
+
+CREATE INDEX IF NOT EXISTS disambiguation_target ON disambiguation_candidate (wiki, lower_title);
@@ -0,0 +1,7 @@
+/* Reference mentions, imported with --with-reference-mentions. anchor is the section anchor of first occurrence
+** linking to a section, surface_forms a JSON object counting displayed texts, such as {"the movie": 2}, and
+** char_offsets a JSON array of occurrence character offsets in wikitext
+*/
+ALTER TABLE article_reference ADD COLUMN IF NOT EXISTS anchor TEXT;
+ALTER TABLE article_reference ADD COLUMN IF NOT EXISTS surface_forms TEXT;
+ALTER TABLE article_reference ADD COLUMN IF NOT EXISTS char_offsets TEXT;
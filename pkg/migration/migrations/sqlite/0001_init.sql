@@ -0,0 +1,39 @@
+/*
+** SQLite flavour of cockroachdb/0001_init.sql, used when importing into a single portable file (--output=sqlite)
+**
+** page_id is declared INTEGER PRIMARY KEY so it aliases SQLite rowid, which FTS5 tables use as key
+*/
+CREATE TABLE IF NOT EXISTS page (
+        page_id INTEGER PRIMARY KEY,
+        title TEXT,
+        lower_title TEXT
+);
+CREATE INDEX IF NOT EXISTS page_title ON page (lower_title);
+
+/*
+** page_content contains plain article content
+*/
+CREATE TABLE IF NOT EXISTS page_content (
+        page_id INTEGER PRIMARY KEY REFERENCES page (page_id) ON DELETE CASCADE,
+        content TEXT
+);
+
+/* article_reference contains references to other articles
+*/
+CREATE TABLE IF NOT EXISTS article_reference (page_id INT, refered_page INT, occurrence INT, reference_index INT, PRIMARY KEY (page_id, refered_page));
+
+/* incoming_reference index allows querying incoming reference for a given article
+*/
+CREATE INDEX IF NOT EXISTS incoming_reference ON article_reference (refered_page);
+
+/* page_nature table contains page content nature (place, person, ...) and infox box template if present
+*/
+CREATE TABLE IF NOT EXISTS page_nature (page_id INTEGER PRIMARY KEY, nature INT, infobox TEXT);
+
+/* Full text indexes on titles and content. They are external content tables, filled once import is done
+** with the 'rebuild' command so they don't slow down inserts.
+**
+** SELECT page.* FROM page_title_fts JOIN page ON page.page_id = page_title_fts.rowid WHERE page_title_fts MATCH 'paris'
+*/
+CREATE VIRTUAL TABLE IF NOT EXISTS page_title_fts USING fts5(title, content='page', content_rowid='page_id');
+CREATE VIRTUAL TABLE IF NOT EXISTS page_content_fts USING fts5(content, content='page_content', content_rowid='page_id');
@@ -0,0 +1,53 @@
+/*
+** Scope every table by wiki (language code: 'en', 'fr', ...) so several languages can be imported in the same file.
+**
+** SQLite cannot alter a primary key, so former tables are renamed and copied into new ones. Existing rows are assumed
+** to come from the default 'en' language. Full text indexes now use implicit rowid since page_id is no longer unique,
+** they are rebuilt at the end of import.
+*/
+DROP TABLE IF EXISTS page_title_fts;
+DROP TABLE IF EXISTS page_content_fts;
+
+ALTER TABLE page RENAME TO page_v1;
+ALTER TABLE page_content RENAME TO page_content_v1;
+ALTER TABLE article_reference RENAME TO article_reference_v1;
+ALTER TABLE page_nature RENAME TO page_nature_v1;
+DROP INDEX IF EXISTS page_title;
+DROP INDEX IF EXISTS incoming_reference;
+
+CREATE TABLE page (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        title TEXT,
+        lower_title TEXT,
+        PRIMARY KEY (wiki, page_id)
+);
+CREATE INDEX page_wiki_title ON page (wiki, lower_title);
+INSERT INTO page (wiki, page_id, title, lower_title) SELECT 'en', page_id, title, lower_title FROM page_v1;
+
+CREATE TABLE page_content (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        content TEXT,
+        PRIMARY KEY (wiki, page_id),
+        FOREIGN KEY (wiki, page_id) REFERENCES page (wiki, page_id) ON DELETE CASCADE
+);
+INSERT INTO page_content (wiki, page_id, content) SELECT 'en', page_id, content FROM page_content_v1;
+
+CREATE TABLE article_reference (wiki TEXT NOT NULL, page_id INT, refered_page INT, occurrence INT, reference_index INT, PRIMARY KEY (wiki, page_id, refered_page));
+CREATE INDEX incoming_wiki_reference ON article_reference (wiki, refered_page);
+INSERT INTO article_reference SELECT 'en', page_id, refered_page, occurrence, reference_index FROM article_reference_v1;
+
+CREATE TABLE page_nature (wiki TEXT NOT NULL, page_id INT, nature INT, infobox TEXT, PRIMARY KEY (wiki, page_id));
+INSERT INTO page_nature SELECT 'en', page_id, nature, infobox FROM page_nature_v1;
+
+DROP TABLE page_content_v1;
+DROP TABLE page_v1;
+DROP TABLE article_reference_v1;
+DROP TABLE page_nature_v1;
+
+/*
+** SELECT page.* FROM page_title_fts JOIN page ON page.rowid = page_title_fts.rowid WHERE page_title_fts MATCH 'paris'
+*/
+CREATE VIRTUAL TABLE page_title_fts USING fts5(title, content='page');
+CREATE VIRTUAL TABLE page_content_fts USING fts5(content, content='page_content');
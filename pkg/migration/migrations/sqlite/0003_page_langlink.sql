@@ -0,0 +1,13 @@
+/* page_langlink contains interlanguage links ([[fr:Paris]]). target_page_id is set once target wiki is imported.
+*/
+CREATE TABLE IF NOT EXISTS page_langlink (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        target_wiki TEXT NOT NULL,
+        target_title TEXT,
+        lower_target_title TEXT,
+        target_page_id INT,
+        PRIMARY KEY (wiki, page_id, target_wiki)
+);
+
+CREATE INDEX IF NOT EXISTS incoming_langlink ON page_langlink (target_wiki, target_page_id);
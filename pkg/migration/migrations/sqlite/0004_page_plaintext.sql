@@ -0,0 +1,9 @@
+/* page_content.plaintext holds article rendered as plain text, without markup, templates, references nor tables.
+** content keeps wikitext. Either can be NULL depending on --page-content-format.
+*/
+ALTER TABLE page_content ADD COLUMN plaintext TEXT;
+
+/* full text search covers both columns, use 'plaintext:paris' to match plain text only
+*/
+DROP TABLE IF EXISTS page_content_fts;
+CREATE VIRTUAL TABLE page_content_fts USING fts5(content, plaintext, content='page_content');
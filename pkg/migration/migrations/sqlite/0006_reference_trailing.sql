@@ -0,0 +1,3 @@
+/* trailing is set on references first found in a trailing section such as See also, imported with --with-trailing-references
+*/
+ALTER TABLE article_reference ADD COLUMN trailing BOOLEAN NOT NULL DEFAULT FALSE;
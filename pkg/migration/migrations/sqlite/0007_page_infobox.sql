@@ -0,0 +1,19 @@
+/* page_infobox contains infobox parameters, ordinal being infobox position in page. Links are replaced by their label
+** and nested templates stripped, values made only of templates such as {{coord}} are kept as wikitext.
+** numeric_value holds the number starting value, if any, so numeric parameters can be compared:
+**
+** SELECT page.title FROM page_infobox JOIN page ON page.wiki = page_infobox.wiki AND page.page_id = page_infobox.page_id
+** WHERE page_infobox.wiki = 'en' AND infobox_type = 'settlement' AND key = 'population_total' AND numeric_value > 1000000
+*/
+CREATE TABLE IF NOT EXISTS page_infobox (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        ordinal INT NOT NULL,
+        infobox_type TEXT,
+        key TEXT NOT NULL,
+        value TEXT,
+        numeric_value REAL,
+        PRIMARY KEY (wiki, page_id, ordinal, key)
+);
+
+CREATE INDEX IF NOT EXISTS infobox_parameter ON page_infobox (wiki, infobox_type, key, numeric_value);
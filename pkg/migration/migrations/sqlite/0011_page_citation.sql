@@ -0,0 +1,24 @@
+/* page_citation contains citation templates of each page, ordinal being citation position in page and section_ordinal
+** the section holding it, see page_section. Missing fields are NULL, doi is lowercased without resolver prefix and
+** isbn has no separator
+*/
+CREATE TABLE IF NOT EXISTS page_citation (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        ordinal INT NOT NULL,
+        section_ordinal INT NOT NULL,
+        template TEXT NOT NULL,
+        title TEXT,
+        author TEXT,
+        publication_date TEXT,
+        doi TEXT,
+        isbn TEXT,
+        url TEXT,
+        publisher TEXT,
+        access_date TEXT,
+        PRIMARY KEY (wiki, page_id, ordinal)
+);
+
+CREATE INDEX IF NOT EXISTS citation_doi ON page_citation (doi);
+
+CREATE INDEX IF NOT EXISTS citation_isbn ON page_citation (isbn);
@@ -0,0 +1,17 @@
+/* page_geo contains coordinates of each page, from {{coord}} templates and infobox latitude and longitude parameters,
+** in decimal degrees. is_primary is set on the coordinate displayed in page title, or the first one.
+** geohash is indexed so radius and bounding box searches are served by a few range scans, see query.WithinRadius
+*/
+CREATE TABLE IF NOT EXISTS page_geo (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        ordinal INT NOT NULL,
+        lat REAL NOT NULL,
+        lon REAL NOT NULL,
+        geohash TEXT NOT NULL,
+        source TEXT NOT NULL,
+        is_primary BOOLEAN NOT NULL DEFAULT false,
+        PRIMARY KEY (wiki, page_id, ordinal)
+);
+
+CREATE INDEX IF NOT EXISTS page_geohash ON page_geo (wiki, geohash);
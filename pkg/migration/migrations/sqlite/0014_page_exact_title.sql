@@ -0,0 +1,5 @@
+/* page_exact_title serves exact title lookups, used to resolve links: titles are normalized following MediaWiki
+** rules, so distinct pages such as 'MIT' and 'Mit' are not confused. page_wiki_title still serves case insensitive
+** lookups on lower_title.
+*/
+CREATE INDEX IF NOT EXISTS page_exact_title ON page (wiki, title);
@@ -0,0 +1,19 @@
+/* page_stats contains size metrics of each page, computed while parsing so stubs can be filtered and results ranked
+** without reading page_content back. word_count counts words of plain text, link counts are distinct targets,
+** citation_count counts footnotes and citation templates outside footnotes, and image_count embedded files
+*/
+CREATE TABLE IF NOT EXISTS page_stats (
+        wiki TEXT NOT NULL,
+        page_id INT NOT NULL,
+        byte_size INT NOT NULL,
+        word_count INT NOT NULL,
+        section_count INT NOT NULL,
+        internal_link_count INT NOT NULL,
+        external_link_count INT NOT NULL,
+        citation_count INT NOT NULL,
+        image_count INT NOT NULL,
+        revision_timestamp TEXT,
+        PRIMARY KEY (wiki, page_id)
+);
+
+CREATE INDEX IF NOT EXISTS page_stats_word_count ON page_stats (wiki, word_count);
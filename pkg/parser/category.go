@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// CategoryLink is a category membership of a page. SortKey is the link label or DEFAULTSORT value, empty if page
+// is sorted by its title.
+type CategoryLink struct {
+	Name    string
+	SortKey string
+}
+
+// defaultSortPrefixes are magic words setting default sort key of category links
+var defaultSortPrefixes = []string{"defaultsort:", "defaultsortkey:", "defaultcategorysort:", "clefdetri:", "sortierung:"}
+
+// Categories returns category memberships of parsed page, in page order, each category once
+func Categories(nodes []Node, ns *Namespaces) []CategoryLink {
+	var links []CategoryLink
+	var defaultSort string
+	seen := make(map[string]bool)
+
+	Walk(nodes, func(n *Node) bool {
+		if n.Type == TemplateNode {
+			name := strings.ToLower(n.Name)
+			for _, prefix := range defaultSortPrefixes {
+				if strings.HasPrefix(name, prefix) {
+					defaultSort = strings.TrimSpace(n.Name[len(prefix):])
+				}
+			}
+			return true
+		}
+
+		name, ok := CategoryName(n, ns)
+		if !ok || name == "" || seen[strings.ToLower(name)] {
+			return true
+		}
+		seen[strings.ToLower(name)] = true
+
+		l := CategoryLink{Name: name}
+		if label := n.Label(); len(label) > 0 {
+			l.SortKey = strings.TrimSpace(Text(label))
+		}
+		links = append(links, l)
+		return true
+	})
+
+	// DEFAULTSORT applies to every category link without sort key, wherever it is in page
+	for i := range links {
+		if links[i].SortKey == "" {
+			links[i].SortKey = defaultSort
+		}
+	}
+
+	return links
+}
+
+// CategoryName returns category of link n, such as 'Capitals in Europe' for [[Category:Capitals in Europe|Paris]].
+// Name is normalized as category page titles are. ok is false if n isn't a category link.
+func CategoryName(n *Node, ns *Namespaces) (string, bool) {
+	if n.Type != LinkNode {
+		return "", false
+	}
+
+	key, name := ns.Split(n.Target)
+	if key != CategoryNamespace {
+		return "", false
+	}
+	return title.Normalize(name, ns.Case), true
+}
+
+// IsCategoryPage returns true if p is in Category namespace, such as 'Category:Capitals in Europe', from page
+// namespace declared in dump
+func IsCategoryPage(p *reader.Page) bool {
+	return p.Ns == CategoryNamespace
+}
+
+// CategoryTitle returns category page title without its namespace prefix, whatever the wiki language
+func CategoryTitle(p *reader.Page) string {
+	i := strings.Index(p.Title, ":")
+	return strings.TrimSpace(p.Title[i+1:])
+}
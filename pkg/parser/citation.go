@@ -0,0 +1,186 @@
+package parser
+
+import "strings"
+
+// citationTemplates lists citation template names of main languages, besides english {{cite *}} and {{citation}}
+var citationTemplates = map[string]bool{
+	// fr
+	"lien web": true, "article": true, "ouvrage": true, "chapitre": true, "lien conférence": true, "lien brisé": true,
+	// de
+	"internetquelle": true, "literatur": true, "cite web": true, "webarchiv": true,
+	// es
+	"cita web": true, "cita libro": true, "cita publicación": true, "cita noticia": true, "cita conferencia": true,
+	// it
+	"cita news": true, "cita pubblicazione": true,
+	// pt
+	"citar web": true, "citar livro": true, "citar periódico": true, "citar jornal": true,
+	// nl
+	"citeer web": true, "citeer boek": true, "citeer journal": true, "citeer nieuws": true,
+}
+
+// IsCitation returns true if template n is a citation template, such as {{cite web}} or {{lien web}}
+func IsCitation(n *Node, ns *Namespaces) bool {
+	name := ns.TemplateName(n.Name)
+	return strings.HasPrefix(name, "cite ") || name == "citation" || citationTemplates[name]
+}
+
+// Citation is a source cited by a citation template. Section is the ordinal of section holding it, see Sections.
+// Fields missing from template are empty.
+type Citation struct {
+	Template   string
+	Section    int
+	Title      string
+	Author     string
+	Date       string
+	DOI        string
+	ISBN       string
+	URL        string
+	Publisher  string
+	AccessDate string
+}
+
+// citationFields maps lowercased citation parameter names of main languages to Citation fields
+var citationFields = map[string]string{
+	"title": "title", "titre": "title", "titel": "title", "título": "title", "titolo": "title",
+	"author": "author", "authors": "author", "auteur": "author", "auteurs": "author", "autor": "author",
+	"autore": "author", "autores": "author", "coauthors": "author",
+	"date": "date", "year": "date", "année": "date", "datum": "date", "jahr": "date", "fecha": "date", "año": "date",
+	"data": "date", "anno": "date", "ano": "date", "jaar": "date",
+	"doi": "doi", "isbn": "isbn", "url": "url", "chapter-url": "url", "chapterurl": "url",
+	"publisher": "publisher", "éditeur": "publisher", "verlag": "publisher", "hrsg": "publisher",
+	"editorial": "publisher", "editore": "publisher", "editora": "publisher", "uitgever": "publisher",
+	"access-date": "access-date", "accessdate": "access-date", "consulté le": "access-date", "abruf": "access-date",
+	"abgerufen": "access-date", "fechaacceso": "access-date", "fecha-acceso": "access-date", "accesso": "access-date",
+	"acessodata": "access-date", "data-acesso": "access-date", "bezochtdatum": "access-date",
+}
+
+// authorNames maps lowercased surname parameters to given name parameters, numbered for multiple authors as in
+// last2/first2
+var authorNames = map[string]string{
+	"last": "first", "nom": "prénom", "nachname": "vorname", "apellidos": "nombre", "apellido": "nombre",
+	"cognome": "nome", "último": "primeiro", "sobrenome": "nome", "achternaam": "voornaam",
+}
+
+// Citations returns citations of parsed page in page order, with the section holding them
+func Citations(nodes []Node, ns *Namespaces) []Citation {
+	var citations []Citation
+	var section int
+
+	for i := range nodes {
+		if nodes[i].Type == HeadingNode {
+			section++
+			continue
+		}
+
+		Walk(nodes[i:i+1], func(n *Node) bool {
+			if n.Type == CommentNode {
+				return false
+			}
+			if n.Type != TemplateNode || !IsCitation(n, ns) {
+				return true
+			}
+
+			c := NewCitation(n, ns)
+			c.Section = section
+			citations = append(citations, c)
+			return false
+		})
+	}
+
+	return citations
+}
+
+// NewCitation returns citation of citation template n
+func NewCitation(n *Node, ns *Namespaces) Citation {
+	c := Citation{Template: strings.TrimSpace(n.Name)}
+
+	var authors []string
+	given := make(map[string]string)
+	var surnames []string
+	for _, p := range n.Params {
+		name := strings.ToLower(strings.TrimSpace(p.Name))
+		value := strings.TrimSpace(Plaintext(p.Value, nil, ns))
+		if value == "" {
+			continue
+		}
+
+		// numbered parameters, such as author2 or last2, are matched without their number
+		base := strings.TrimRight(name, "0123456789")
+		if _, ok := authorNames[base]; ok {
+			surnames = append(surnames, name)
+			given[name] = value
+			continue
+		}
+		if field, ok := citationFields[base]; ok && field == "author" {
+			authors = append(authors, value)
+			continue
+		}
+
+		switch citationFields[name] {
+		case "title":
+			c.Title = value
+		case "date":
+			if c.Date == "" {
+				c.Date = value
+			}
+		case "doi":
+			c.DOI = NormalizeDOI(value)
+		case "isbn":
+			c.ISBN = NormalizeISBN(value)
+		case "url":
+			if c.URL == "" {
+				c.URL = strings.TrimSpace(rawValue(p.Value))
+			}
+		case "publisher":
+			c.Publisher = value
+		case "access-date":
+			c.AccessDate = value
+		}
+	}
+
+	// authors given as surname and given name, such as last1 and first1
+	for _, name := range surnames {
+		base := strings.TrimRight(name, "0123456789")
+		number := name[len(base):]
+		author := given[name]
+		if first := namedParam(n, authorNames[base]+number, ns); first != "" {
+			author += ", " + first
+		}
+		authors = append(authors, author)
+	}
+	c.Author = strings.Join(authors, "; ")
+
+	return c
+}
+
+// namedParam returns plain text value of parameter name of n, parameter name case being ignored
+func namedParam(n *Node, name string, ns *Namespaces) string {
+	for _, p := range n.Params {
+		if strings.EqualFold(strings.TrimSpace(p.Name), name) {
+			return strings.TrimSpace(Plaintext(p.Value, nil, ns))
+		}
+	}
+	return ""
+}
+
+// NormalizeDOI returns lowercased DOI without resolver or 'doi:' prefix, as in '10.1000/xyz123'
+func NormalizeDOI(doi string) string {
+	doi = strings.ToLower(strings.TrimSpace(doi))
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		doi = strings.TrimPrefix(doi, prefix)
+	}
+	return strings.TrimSpace(doi)
+}
+
+// NormalizeISBN returns ISBN digits, without separators, as in '9783161484100'
+func NormalizeISBN(isbn string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == 'x' || r == 'X':
+			return 'X'
+		}
+		return -1
+	}, isbn)
+}
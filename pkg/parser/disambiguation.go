@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// DisambiguationTemplates lists per language templates marking a disambiguation page, lowercased
+var DisambiguationTemplates = map[string][]string{
+	"en": {"disambiguation", "disambig", "dab", "disamb", "dis", "hndis", "geodis", "numberdis", "letter disambiguation", "place name disambiguation", "school disambiguation"},
+	"fr": {"homonymie", "homonymes", "patronymie", "toponymie", "bandeau standard pour page d'homonymie"},
+	"de": {"begriffsklärung"},
+	"es": {"desambiguación", "desambig", "des"},
+	"it": {"disambigua", "disambiguazione"},
+	"pt": {"desambiguação", "desambig", "dab"},
+	"nl": {"dp", "dpintro", "dp-naam"},
+}
+
+// DisambiguationMagicWord marks a disambiguation page, whatever the language
+const DisambiguationMagicWord = "__DISAMBIG__"
+
+// DisambiguationTemplateNames returns disambiguation templates of language, english ones if language is unknown
+func DisambiguationTemplateNames(language string) []string {
+	if templates, ok := DisambiguationTemplates[language]; ok {
+		return templates
+	}
+	return DisambiguationTemplates["en"]
+}
+
+// IsDisambiguation returns true if parsed page transcludes one of templates or contains __DISAMBIG__ magic word
+func IsDisambiguation(nodes []Node, templates []string, ns *Namespaces) bool {
+	var found bool
+
+	Walk(nodes, func(n *Node) bool {
+		if found || n.Type == CommentNode {
+			return false
+		}
+
+		switch n.Type {
+		case TextNode:
+			found = strings.Contains(n.Text, DisambiguationMagicWord)
+		case TemplateNode:
+			name := ns.TemplateName(n.Name)
+			for _, t := range templates {
+				if name == t {
+					found = true
+				}
+			}
+		}
+		return true
+	})
+
+	return found
+}
+
+// DisambiguationCandidates returns titles of articles linked from a disambiguation page, in page order and each once
+func DisambiguationCandidates(nodes []Node, ns *Namespaces) []string {
+	var candidates []string
+	seen := make(map[string]bool)
+
+	Walk(nodes, func(n *Node) bool {
+		if n.Type == CommentNode {
+			return false
+		}
+		if n.Type != LinkNode {
+			return true
+		}
+		l := ns.ClassifyLink(n.Target)
+		if l.Kind != ArticleKind {
+			return true
+		}
+
+		candidate := title.Normalize(l.Title, ns.Case)
+		if candidate == "" || seen[candidate] {
+			return true
+		}
+		seen[candidate] = true
+		candidates = append(candidates, candidate)
+		return true
+	})
+
+	return candidates
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ExternalLink is a link to another site, either [http://target label] or url parameter of a citation template.
+// InCitation is set for links found in citation templates or in references.
+type ExternalLink struct {
+	URL        string
+	Domain     string
+	InCitation bool
+}
+
+// citationURLParams are citation template parameters holding cited url
+var citationURLParams = []string{"url", "URL", "chapter-url", "chapterurl"}
+
+// ExternalLinks returns external links of parsed page in page order. Links of same url are returned once, cited if
+// any of them is.
+func ExternalLinks(nodes []Node, ns *Namespaces) []ExternalLink {
+	e := &extlinkExtractor{seen: make(map[string]int), ns: ns}
+	e.extract(nodes, false)
+	return e.links
+}
+
+type extlinkExtractor struct {
+	links []ExternalLink
+	seen  map[string]int
+	ns    *Namespaces
+}
+
+func (e *extlinkExtractor) extract(nodes []Node, inCitation bool) {
+	for i := range nodes {
+		n := &nodes[i]
+
+		switch n.Type {
+		case CommentNode:
+			continue
+		case ExternalLinkNode:
+			e.add(n.Target, inCitation)
+		case TemplateNode:
+			if IsCitation(n, e.ns) {
+				for _, name := range citationURLParams {
+					if value := n.Param(name); value != nil {
+						e.add(strings.TrimSpace(rawValue(value)), true)
+					}
+				}
+				e.extractParams(n, true)
+				continue
+			}
+		case TagNode:
+			if n.Name == "ref" {
+				e.extract(n.Children, true)
+				continue
+			}
+		}
+
+		e.extractParams(n, inCitation)
+		e.extract(n.Children, inCitation)
+	}
+}
+
+func (e *extlinkExtractor) extractParams(n *Node, inCitation bool) {
+	for _, p := range n.Params {
+		e.extract(p.Value, inCitation)
+	}
+}
+
+func (e *extlinkExtractor) add(target string, inCitation bool) {
+	if externalURL(target) != target {
+		return
+	}
+
+	if i, ok := e.seen[target]; ok {
+		e.links[i].InCitation = e.links[i].InCitation || inCitation
+		return
+	}
+
+	e.seen[target] = len(e.links)
+	e.links = append(e.links, ExternalLink{URL: target, Domain: Domain(target), InCitation: inCitation})
+}
+
+// Domain returns lowercased host of url u without 'www.' prefix, empty if u has no host such as mailto: links
+func Domain(u string) string {
+	// protocol relative urls use page protocol
+	if strings.HasPrefix(u, "//") {
+		u = "https:" + u
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
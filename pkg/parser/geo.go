@@ -0,0 +1,250 @@
+package parser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Coordinate is a geographic coordinate in decimal degrees, found in a {{coord}} template or in infobox latitude and
+// longitude parameters. Primary is set for the coordinate displayed as page title coordinate.
+type Coordinate struct {
+	Lat     float64
+	Lon     float64
+	Source  string
+	Primary bool
+}
+
+const (
+	CoordSource   = "coord"
+	InfoboxSource = "infobox"
+)
+
+// coordTemplates lists coordinate template names of main languages
+var coordTemplates = map[string]bool{
+	"coord": true, "coor": true, "coor dms": true, "coor d": true, "coordinates": true, "coordinate": true,
+	"koordinate": true, "coordenadas": true, "coördinaten": true,
+}
+
+// eastO lists languages where hemisphere letter O is east, Ost in german and Oost in dutch. Elsewhere O is west,
+// such as Ouest in french, Oeste in spanish and portuguese or Ovest in italian.
+var eastO = map[string]bool{"de": true, "nl": true}
+
+// infoboxCoordParams lists infobox latitude and longitude parameters, as degrees, minutes, seconds and hemisphere
+var infoboxCoordParams = [][2][4]string{
+	{{"latitude"}, {"longitude"}},
+	{{"lat"}, {"long"}},
+	{{"lat"}, {"lon"}},
+	{{"lat_deg", "lat_min", "lat_sec", "lat_dir"}, {"lon_deg", "lon_min", "lon_sec", "lon_dir"}},
+	{{"latd", "latm", "lats", "latns"}, {"longd", "longm", "longs", "longew"}},
+}
+
+// Coordinates returns coordinates of parsed page in page order, each position once. First coordinate is primary if
+// none is displayed in title. Hemisphere letters are read following language conventions, see eastO.
+func Coordinates(nodes []Node, ns *Namespaces, language string) []Coordinate {
+	var coordinates []Coordinate
+	var primary bool
+
+	add := func(c Coordinate) {
+		for _, other := range coordinates {
+			if math.Abs(other.Lat-c.Lat) < 1e-6 && math.Abs(other.Lon-c.Lon) < 1e-6 {
+				return
+			}
+		}
+		if c.Primary {
+			if primary {
+				c.Primary = false
+			}
+			primary = true
+		}
+		coordinates = append(coordinates, c)
+	}
+
+	Walk(nodes, func(n *Node) bool {
+		if n.Type == CommentNode {
+			return false
+		}
+		if n.Type != TemplateNode {
+			return true
+		}
+
+		if coordTemplates[ns.TemplateName(n.Name)] {
+			if c, ok := coordTemplate(n, ns, language); ok {
+				add(c)
+			}
+			return false
+		}
+
+		if IsInfobox(n, ns) {
+			if c, ok := infoboxCoordinate(n, ns, language); ok {
+				add(c)
+			}
+		}
+		return true
+	})
+
+	if len(coordinates) > 0 && !primary {
+		coordinates[0].Primary = true
+	}
+
+	return coordinates
+}
+
+// coordTemplate parses {{coord}} forms: decimal {{coord|48.8567|2.3508}}, decimal with hemispheres
+// {{coord|48.8567|N|2.3508|E}}, degrees and minutes {{coord|48|51|N|2|21|E}}, degrees, minutes and seconds
+// {{coord|48|51|24|N|2|21|08|E}}, and german {{Coordinate|NS=48/51/24/N|EW=2/21/8/E}}
+func coordTemplate(n *Node, ns *Namespaces, language string) (Coordinate, bool) {
+	c := Coordinate{Source: CoordSource}
+	if display := namedParam(n, "display", ns); strings.Contains(display, "title") || display == "t" || display == "it" {
+		c.Primary = true
+	}
+
+	if northSouth, eastWest := namedParam(n, "NS", ns), namedParam(n, "EW", ns); northSouth != "" && eastWest != "" {
+		var ok bool
+		c.Lat, ok = parseDMS(strings.Split(northSouth, "/"), language)
+		if !ok {
+			return c, false
+		}
+		c.Lon, ok = parseDMS(strings.Split(eastWest, "/"), language)
+		return c, ok && validCoordinate(c.Lat, c.Lon)
+	}
+
+	// positional parameters, parameters such as type:city excluded
+	var parts []string
+	for _, p := range n.Params {
+		value := strings.TrimSpace(Text(p.Value))
+		if p.Named || strings.Contains(value, ":") || value == "" {
+			continue
+		}
+		parts = append(parts, value)
+	}
+
+	latEnd := hemisphereIndex(parts, "NS")
+	if latEnd < 0 {
+		// decimal degrees
+		if len(parts) < 2 {
+			return c, false
+		}
+		lat, err := parseDegrees(parts[0])
+		if err != nil {
+			return c, false
+		}
+		lon, err := parseDegrees(parts[1])
+		if err != nil {
+			return c, false
+		}
+		c.Lat, c.Lon = lat, lon
+		return c, validCoordinate(c.Lat, c.Lon)
+	}
+
+	lonEnd := hemisphereIndex(parts[latEnd+1:], "EWO")
+	if lonEnd < 0 {
+		return c, false
+	}
+
+	var ok bool
+	c.Lat, ok = parseDMS(parts[:latEnd+1], language)
+	if !ok {
+		return c, false
+	}
+	c.Lon, ok = parseDMS(parts[latEnd+1:latEnd+lonEnd+2], language)
+	return c, ok && validCoordinate(c.Lat, c.Lon)
+}
+
+// infoboxCoordinate returns coordinate given by infobox n latitude and longitude parameters
+func infoboxCoordinate(n *Node, ns *Namespaces, language string) (Coordinate, bool) {
+	c := Coordinate{Source: InfoboxSource}
+
+	for _, params := range infoboxCoordParams {
+		var values [2][]string
+		for i, names := range params {
+			for _, name := range names {
+				if name == "" {
+					continue
+				}
+				if value := namedParam(n, name, ns); value != "" {
+					values[i] = append(values[i], value)
+				}
+			}
+		}
+		if len(values[0]) == 0 || len(values[1]) == 0 {
+			continue
+		}
+
+		var lat, lon bool
+		c.Lat, lat = parseDMS(values[0], language)
+		c.Lon, lon = parseDMS(values[1], language)
+		if lat && lon && validCoordinate(c.Lat, c.Lon) {
+			return c, true
+		}
+	}
+
+	return c, false
+}
+
+// hemisphereIndex returns index of first hemisphere letter among the first 4 parts, -1 if none
+func hemisphereIndex(parts []string, letters string) int {
+	for i := 0; i < len(parts) && i < 4; i++ {
+		if len(parts[i]) == 1 && strings.Contains(letters, strings.ToUpper(parts[i])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseDMS returns decimal degrees of degrees, minutes and seconds parts, optionally followed by hemisphere letter
+// of language
+func parseDMS(parts []string, language string) (float64, bool) {
+	if len(parts) == 0 {
+		return 0, false
+	}
+
+	sign := 1.0
+	last := strings.ToUpper(strings.TrimSpace(parts[len(parts)-1]))
+	switch last {
+	case "S", "W":
+		sign = -1
+		parts = parts[:len(parts)-1]
+	case "O":
+		if !eastO[language] {
+			sign = -1
+		}
+		parts = parts[:len(parts)-1]
+	case "N", "E":
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 || len(parts) > 3 {
+		return 0, false
+	}
+
+	var degrees float64
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		v, err := parseDegrees(part)
+		if err != nil || (i > 0 && (v < 0 || v >= 60)) {
+			return 0, false
+		}
+		// minutes and seconds have the sign of degrees, as in -33|52
+		if v < 0 {
+			sign, v = -sign, -v
+		}
+		degrees += v / math.Pow(60, float64(i))
+	}
+
+	return sign * degrees, true
+}
+
+// parseDegrees parses a decimal number, accepting decimal comma and unicode minus
+func parseDegrees(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.Replace(s, "−", "-", 1)
+	s = strings.Replace(s, ",", ".", 1)
+	s = strings.TrimRight(s, "°′″'\"")
+	return strconv.ParseFloat(s, 64)
+}
+
+func validCoordinate(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
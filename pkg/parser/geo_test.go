@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCoordinates(t *testing.T) {
+	cases := []struct {
+		name     string
+		language string
+		text     string
+		lat, lon float64
+	}{
+		{"decimal", "en", "{{coord|48.8567|2.3508}}", 48.8567, 2.3508},
+		{"decimal hemispheres", "en", "{{coord|48.8567|N|2.3508|E}}", 48.8567, 2.3508},
+		{"west", "en", "{{coord|40|43|N|74|0|W}}", 40.716667, -74},
+		{"south", "en", "{{coord|33|52|S|151|13|E}}", -33.866667, 151.216667},
+		{"south west", "en", "{{coord|22|54|30|S|43|11|47|W}}", -22.908333, -43.196389},
+		{"french ouest", "fr", "{{coord|48|51|N|2|21|O}}", 48.85, -2.35},
+		{"spanish oeste", "es", "{{coord|40|25|N|3|42|O}}", 40.416667, -3.7},
+		{"french est", "fr", "{{coord|48|51|N|2|21|E}}", 48.85, 2.35},
+		{"german ost", "de", "{{coord|52|31|N|13|24|O}}", 52.516667, 13.4},
+		{"german NS EW", "de", "{{Coordinate|NS=52/31/12/N|EW=13/24/18/O}}", 52.52, 13.405},
+		{"german NS EW west", "de", "{{Coordinate|NS=40/43/N|EW=74/0/W}}", 40.716667, -74},
+		{"french NS EW ouest", "fr", "{{Coordinate|NS=48/51/N|EW=2/21/O}}", 48.85, -2.35},
+		{"infobox direction", "fr", "{{Infobox Commune|lat_deg=48|lat_min=51|lat_dir=N|lon_deg=2|lon_min=21|lon_dir=O}}", 48.85, -2.35},
+	}
+
+	for _, c := range cases {
+		coordinates := Coordinates(Parse(c.text), DefaultNamespaces, c.language)
+		if len(coordinates) != 1 {
+			t.Errorf("%s: Coordinates(%q) = %+v, want one coordinate", c.name, c.text, coordinates)
+			continue
+		}
+		got := coordinates[0]
+		if math.Abs(got.Lat-c.lat) > 1e-5 || math.Abs(got.Lon-c.lon) > 1e-5 {
+			t.Errorf("%s: Coordinates(%q) = %f, %f, want %f, %f", c.name, c.text, got.Lat, got.Lon, c.lat, c.lon)
+		}
+	}
+}
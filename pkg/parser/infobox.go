@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Infobox is an infobox template with its parameters, in page order. Type is template name without 'Infobox' prefix,
+// lowercased, such as 'settlement' for {{Infobox settlement}}.
+type Infobox struct {
+	Name   string
+	Type   string
+	Params []InfoboxParam
+}
+
+// InfoboxParam is an infobox parameter. Value is rendered as plain text, or kept as wikitext if it is made only of
+// stripped templates, such as {{coord}}.
+type InfoboxParam struct {
+	Key   string
+	Value string
+}
+
+// Infoboxes returns infoboxes of parsed page in page order, nested ones included. Parameters without value are skipped.
+func Infoboxes(nodes []Node, templates []string, ns *Namespaces) []Infobox {
+	var infoboxes []Infobox
+
+	Walk(nodes, func(n *Node) bool {
+		if n.Type != TemplateNode || !IsInfobox(n, ns) {
+			return true
+		}
+
+		infobox := Infobox{
+			Name: strings.TrimSpace(n.Name),
+			Type: InfoboxType(n.Name, ns),
+		}
+
+		keys := make(map[string]int)
+		for _, p := range n.Params {
+			value := strings.TrimSpace(Plaintext(p.Value, templates, ns))
+			if value == "" {
+				value = strings.TrimSpace(Text(p.Value))
+				if value != "" {
+					value = strings.TrimSpace(rawValue(p.Value))
+				}
+			}
+			if value == "" {
+				continue
+			}
+
+			key := strings.TrimSpace(p.Name)
+			// last value wins, as in MediaWiki
+			if i, ok := keys[key]; ok {
+				infobox.Params[i].Value = value
+				continue
+			}
+			keys[key] = len(infobox.Params)
+			infobox.Params = append(infobox.Params, InfoboxParam{Key: key, Value: value})
+		}
+
+		infoboxes = append(infoboxes, infobox)
+		return true
+	})
+
+	return infoboxes
+}
+
+// IsInfobox returns true if template n is an infobox
+func IsInfobox(n *Node, ns *Namespaces) bool {
+	return strings.HasPrefix(ns.TemplateName(n.Name), "infobox")
+}
+
+// InfoboxType returns infobox template name without 'Infobox' prefix, lowercased
+func InfoboxType(name string, ns *Namespaces) string {
+	return strings.TrimSpace(strings.TrimPrefix(ns.TemplateName(name), "infobox"))
+}
+
+// rawValue returns wikitext of nodes, comments excluded
+func rawValue(nodes []Node) string {
+	var b strings.Builder
+	for i := range nodes {
+		if nodes[i].Type == CommentNode {
+			continue
+		}
+		b.WriteString(nodes[i].Raw)
+	}
+	return b.String()
+}
+
+// decimalComma lists languages using ',' as decimal separator
+var decimalComma = map[string]bool{
+	"fr": true, "de": true, "es": true, "it": true, "pt": true, "nl": true, "ru": true, "pl": true, "sv": true,
+	"cs": true, "da": true, "fi": true, "no": true, "ro": true, "tr": true, "uk": true, "ca": true, "hu": true,
+}
+
+var (
+	// digits grouped by thousands, or not grouped, followed by decimals
+	numberRe = regexp.MustCompile(`^[~≈<>c.$€£¥ ]*([-+−]?(?:[0-9]{1,3}(?:[,.'\s\x{00a0}\x{202f}\x{2009}][0-9]{3})+|[0-9]+)(?:[.,][0-9]+)?)(.*)`)
+
+	numberMultipliers = map[string]float64{
+		"thousand": 1e3, "million": 1e6, "millions": 1e6, "billion": 1e9, "billions": 1e9, "trillion": 1e12,
+		"mille": 1e3, "milliard": 1e9, "milliards": 1e9,
+		"tausend": 1e3, "mio.": 1e6, "millionen": 1e6, "mrd.": 1e9, "milliarde": 1e9, "milliarden": 1e9,
+	}
+)
+
+// ParseNumber returns number starting infobox value s, written with language conventions, such as
+// '2,165,423' in english, '2 165 423' in french or '1.2 million'.
+func ParseNumber(s string, language string) (float64, bool) {
+	m := numberRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+
+	number := strings.Replace(m[1], "−", "-", 1)
+	number = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\'', '\u00a0', '\u202f', '\u2009':
+			return -1
+		}
+		return r
+	}, number)
+
+	if decimalComma[language] {
+		number = strings.Replace(number, ".", "", -1)
+		number = strings.Replace(number, ",", ".", -1)
+	} else {
+		number = strings.Replace(number, ",", "", -1)
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if rest := strings.Fields(strings.ToLower(m[2])); len(rest) > 0 {
+		if mult, ok := numberMultipliers[rest[0]]; ok {
+			f *= mult
+		}
+	}
+
+	return f, true
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// LangLink is an interlanguage link, such as [[fr:Paris]] on english Paris article
+type LangLink struct {
+	Language string
+	Title    string
+}
+
+// languages lists Wikipedia language codes, used as interlanguage link prefixes
+var languages = map[string]bool{}
+
+func init() {
+	codes := `aa ab ace ady af ak als alt am ami an ang anp ar arc ary arz as ast atj av avk awa ay az azb
+		ba ban bar bat-smg bbc bcl be be-tarask be-x-old bew bg bh bi bjn blk bm bn bo bpy br bs bug bxr
+		ca cbk-zam cdo ce ceb ch cho chr chy ckb co cr crh cs csb cu cv cy
+		da dag de dga din diq dsb dtp dty dv dz ee el eml en eo es et eu ext
+		fa fat ff fi fiu-vro fj fo fon fr frp frr fur fy ga gag gan gcr gd gl glk gn gom gor got gpe gu guc gur guw gv
+		ha hak haw he hi hif ho hr hsb ht hu hy hyw hz ia id ie ig igl ii ik ilo inh io is it iu
+		ja jam jbo jv ka kaa kab kbd kbp kcg kg kge ki kj kk kl km kn knc ko koi kr krc ks ksh ku kus kv kw ky
+		la lad lb lbe lez lfn lg li lij lld lmo ln lo lrc lt ltg lv
+		mad mai map-bms mdf mg mh mhr mi min mk ml mn mni mnw mo mos mr mrj ms mt mus mwl my myv mzn
+		na nah nap nds nds-nl ne new ng nia nl nn no nov nqo nr nrm nso nup nv ny
+		oc olo om or os pa pag pam pap pcd pcm pdc pfl pi pih pl pms pnb pnt ps pt pwn qu
+		rm rmy rn ro roa-rup roa-tara rsk ru rue rup rw sa sah sat sc scn sco sd se sg sgs sh shi shn si simple sk skr sl sm smn sn so sq sr srn ss st stq su sv sw syl szl szy
+		ta tay tcy tdd te tet tg th ti tig tk tl tly tn to tpi tr trv ts tt tum tw ty tyv
+		udm ug uk ur uz ve vec vep vi vls vo vro wa war wo wuu xal xh xmf yi yo yue za zea zgh zh zh-classical zh-min-nan zh-yue zu`
+
+	for _, c := range strings.Fields(codes) {
+		languages[c] = true
+	}
+}
+
+// IsLanguageCode returns true if s is a Wikipedia language code
+func IsLanguageCode(s string) bool {
+	return languages[strings.ToLower(s)]
+}
+
+// PageLangLinks returns interlanguage links of page, keeping only the first link for each language.
+// Links prefixed by a colon ([[:fr:Paris]]) are inline links to another wiki, not interlanguage links.
+func PageLangLinks(p *reader.Page) []LangLink {
+	return LangLinks(Parse(p.Text))
+}
+
+// LangLinks extracts interlanguage links of parsed page, keeping first link of each language
+func LangLinks(nodes []Node) []LangLink {
+	var links []LangLink
+	seen := make(map[string]bool)
+
+	Walk(nodes, func(n *Node) bool {
+		if n.Type != LinkNode {
+			return true
+		}
+
+		l, ok := langLink(n)
+		if !ok || seen[l.Language] {
+			return true
+		}
+
+		seen[l.Language] = true
+		links = append(links, l)
+		return true
+	})
+
+	return links
+}
+
+// langLink returns interlanguage link of link node n. Links prefixed with ':' are inline links to another language and not interlanguage links.
+func langLink(n *Node) (LangLink, bool) {
+	t := strings.SplitN(n.Target, ":", 2)
+	if len(t) != 2 {
+		return LangLink{}, false
+	}
+
+	lang := strings.ToLower(strings.TrimSpace(t[0]))
+	if !IsLanguageCode(lang) {
+		return LangLink{}, false
+	}
+
+	// section anchor isn't part of target page title, as in [[fr:Paris#Histoire]]
+	target := t[1]
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+
+	// Wikipedia titles are first letter case insensitive in every language
+	target = title.Normalize(target, title.FirstLetter)
+	if target == "" {
+		return LangLink{}, false
+	}
+
+	return LangLink{Language: lang, Title: target}, true
+}
@@ -0,0 +1,17 @@
+package parser
+
+import "testing"
+
+func TestLangLinks(t *testing.T) {
+	links := LangLinks(Parse("[[fr:Paris#Histoire]] [[de:paris_(Stadt)]] [[:es:Madrid]] [[it:#Storia]] [[fr:Lyon]]"))
+	want := []LangLink{{Language: "fr", Title: "Paris"}, {Language: "de", Title: "Paris (Stadt)"}}
+
+	if len(links) != len(want) {
+		t.Fatalf("LangLinks = %+v, want %+v", links, want)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("LangLinks[%d] = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+}
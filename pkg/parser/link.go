@@ -0,0 +1,112 @@
+package parser
+
+import "strings"
+
+// LinkKind is the kind of page a wiki link points to
+type LinkKind int
+
+const (
+	// ArticleKind points to a main namespace page, such as [[Star Wars: Episode IV]]
+	ArticleKind LinkKind = iota
+	// FileKind embeds a file, such as [[File:Paris.jpg]] or [[Media:Paris.jpg]]
+	FileKind
+	// CategoryKind adds page to a category, such as [[Category:Capitals]], or points to it when inline
+	CategoryKind
+	// InterwikiKind points to a sister project, such as [[wikt:paris]]
+	InterwikiKind
+	// InterlanguageKind points to same article in another language, such as [[fr:Paris]], or to another language
+	// article when inline
+	InterlanguageKind
+	// SpecialKind points to a page outside main namespace, such as [[Special:Random]], [[Wikipedia:About]] or
+	// [[Template:Coord]]
+	SpecialKind
+)
+
+func (k LinkKind) String() string {
+	switch k {
+	case ArticleKind:
+		return "article"
+	case FileKind:
+		return "file"
+	case CategoryKind:
+		return "category"
+	case InterwikiKind:
+		return "interwiki"
+	case InterlanguageKind:
+		return "interlanguage"
+	case SpecialKind:
+		return "special"
+	}
+	return "unknown"
+}
+
+// Link is a classified link target. Title has no namespace, interwiki nor language prefix, which is in Prefix.
+// Inline is set for links starting with a colon, such as [[:Category:Capitals]], which are displayed as links
+// instead of categorizing page or embedding file.
+type Link struct {
+	Kind      LinkKind
+	Namespace int
+	Prefix    string
+	Title     string
+	Anchor    string
+	Inline    bool
+}
+
+// DefaultInterwikis lists interwiki prefixes of Wikimedia sister projects
+var DefaultInterwikis = map[string]bool{
+	"wiktionary": true, "wikt": true, "wikisource": true, "s": true, "wikiquote": true, "q": true,
+	"wikibooks": true, "b": true, "wikinews": true, "n": true, "wikiversity": true, "v": true,
+	"wikivoyage": true, "voy": true, "wikispecies": true, "species": true, "wikidata": true, "d": true,
+	"commons": true, "c": true, "meta": true, "m": true, "metawikimedia": true, "mediawikiwiki": true, "mw": true,
+	"wikimedia": true, "wmf": true, "foundation": true, "phabricator": true, "phab": true, "outreach": true,
+	"incubator": true, "wikitech": true,
+}
+
+// ClassifyLink returns kind and title of link target, namespaces being those of ns
+func (ns *Namespaces) ClassifyLink(target string) Link {
+	var l Link
+	target = strings.TrimSpace(strings.Replace(target, "_", " ", -1))
+	if strings.HasPrefix(target, ":") {
+		l.Inline = true
+		target = strings.TrimSpace(target[1:])
+	}
+
+	if i := strings.Index(target, "#"); i >= 0 {
+		l.Anchor = strings.TrimSpace(target[i+1:])
+		target = strings.TrimSpace(target[:i])
+	}
+
+	l.Title = target
+	t := strings.SplitN(target, ":", 2)
+	if len(t) != 2 {
+		return l
+	}
+	prefix := normalizeNamespace(t[0])
+	rest := strings.TrimSpace(t[1])
+
+	if key, ok := ns.keys[prefix]; ok {
+		l.Namespace, l.Prefix, l.Title = key, prefix, rest
+		switch key {
+		case FileNamespace, MediaNamespace:
+			l.Kind = FileKind
+		case CategoryNamespace:
+			l.Kind = CategoryKind
+		default:
+			l.Kind = SpecialKind
+		}
+		return l
+	}
+
+	if IsLanguageCode(prefix) {
+		l.Kind, l.Prefix, l.Title = InterlanguageKind, prefix, rest
+		return l
+	}
+
+	if DefaultInterwikis[prefix] {
+		l.Kind, l.Prefix, l.Title = InterwikiKind, prefix, rest
+		return l
+	}
+
+	// colon is part of title, as in [[Star Wars: Episode IV]]
+	return l
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// itSiteInfo is the siteinfo of an italian dump, localized namespace names only
+const itSiteInfo = `<siteinfo>
+  <sitename>Wikipedia</sitename>
+  <dbname>itwiki</dbname>
+  <case>first-letter</case>
+  <namespaces>
+    <namespace key="-2" case="first-letter">Media</namespace>
+    <namespace key="-1" case="first-letter">Speciale</namespace>
+    <namespace key="0" case="first-letter" />
+    <namespace key="1" case="first-letter">Discussione</namespace>
+    <namespace key="2" case="first-letter">Utente</namespace>
+    <namespace key="4" case="first-letter">Wikipedia</namespace>
+    <namespace key="6" case="first-letter">File</namespace>
+    <namespace key="10" case="first-letter">Template</namespace>
+    <namespace key="14" case="first-letter">Categoria</namespace>
+    <namespace key="100" case="first-letter">Portale</namespace>
+  </namespaces>
+</siteinfo>`
+
+func TestClassifyLink(t *testing.T) {
+	var si reader.SiteInfo
+	err := xml.Unmarshal([]byte(itSiteInfo), &si)
+	if err != nil {
+		t.Fatalf("Unmarshal siteinfo: %s", err)
+	}
+	it := NewNamespaces(&si)
+
+	cases := []struct {
+		name   string
+		ns     *Namespaces
+		target string
+		want   Link
+	}{
+		{"article", DefaultNamespaces, "Paris", Link{Kind: ArticleKind, Title: "Paris"}},
+		{"article anchor", DefaultNamespaces, "Paris#History", Link{Kind: ArticleKind, Title: "Paris", Anchor: "History"}},
+		{"underscores", DefaultNamespaces, "Eiffel_Tower", Link{Kind: ArticleKind, Title: "Eiffel Tower"}},
+		{"colon in title", DefaultNamespaces, "Star Wars: Episode IV", Link{Kind: ArticleKind, Title: "Star Wars: Episode IV"}},
+		{"inline article", DefaultNamespaces, ":Paris", Link{Kind: ArticleKind, Title: "Paris", Inline: true}},
+		{"file", DefaultNamespaces, "File:Paris.jpg", Link{Kind: FileKind, Namespace: FileNamespace, Prefix: "file", Title: "Paris.jpg"}},
+		{"image alias", DefaultNamespaces, "Image:Paris.jpg", Link{Kind: FileKind, Namespace: FileNamespace, Prefix: "image", Title: "Paris.jpg"}},
+		{"media", DefaultNamespaces, "Media:Anthem.ogg", Link{Kind: FileKind, Namespace: MediaNamespace, Prefix: "media", Title: "Anthem.ogg"}},
+		{"inline file", DefaultNamespaces, ":File:Paris.jpg", Link{Kind: FileKind, Namespace: FileNamespace, Prefix: "file", Title: "Paris.jpg", Inline: true}},
+		{"category", DefaultNamespaces, "Category:Capitals", Link{Kind: CategoryKind, Namespace: CategoryNamespace, Prefix: "category", Title: "Capitals"}},
+		{"inline category", DefaultNamespaces, ":Category:Capitals", Link{Kind: CategoryKind, Namespace: CategoryNamespace, Prefix: "category", Title: "Capitals", Inline: true}},
+		{"lowercase category", DefaultNamespaces, "category: Capitals", Link{Kind: CategoryKind, Namespace: CategoryNamespace, Prefix: "category", Title: "Capitals"}},
+		{"template", DefaultNamespaces, "Template:Coord", Link{Kind: SpecialKind, Namespace: TemplateNamespace, Prefix: "template", Title: "Coord"}},
+		{"special", DefaultNamespaces, "Special:Random", Link{Kind: SpecialKind, Namespace: SpecialNamespace, Prefix: "special", Title: "Random"}},
+		{"project", DefaultNamespaces, "Wikipedia:About", Link{Kind: SpecialKind, Namespace: ProjectNamespace, Prefix: "wikipedia", Title: "About"}},
+		{"interwiki", DefaultNamespaces, "wikt:paris", Link{Kind: InterwikiKind, Prefix: "wikt", Title: "paris"}},
+		{"interwiki commons", DefaultNamespaces, "commons:Paris", Link{Kind: InterwikiKind, Prefix: "commons", Title: "Paris"}},
+		{"interlanguage", DefaultNamespaces, "fr:Paris", Link{Kind: InterlanguageKind, Prefix: "fr", Title: "Paris"}},
+		{"inline interlanguage", DefaultNamespaces, ":de:Paris", Link{Kind: InterlanguageKind, Prefix: "de", Title: "Paris", Inline: true}},
+		{"french category", DefaultNamespaces, "Catégorie:Capitale", Link{Kind: CategoryKind, Namespace: CategoryNamespace, Prefix: "catégorie", Title: "Capitale"}},
+		{"localized category", it, "Categoria:Capitali europee", Link{Kind: CategoryKind, Namespace: CategoryNamespace, Prefix: "categoria", Title: "Capitali europee"}},
+		{"localized template", it, "Template:Coord", Link{Kind: SpecialKind, Namespace: TemplateNamespace, Prefix: "template", Title: "Coord"}},
+		{"localized special", it, "Speciale:Casuale", Link{Kind: SpecialKind, Namespace: SpecialNamespace, Prefix: "speciale", Title: "Casuale"}},
+		{"localized portal", it, "Portale:Roma", Link{Kind: SpecialKind, Namespace: PortalNamespace, Prefix: "portale", Title: "Roma"}},
+		{"canonical name in localized wiki", it, "Category:Capitals", Link{Kind: CategoryKind, Namespace: CategoryNamespace, Prefix: "category", Title: "Capitals"}},
+		{"other language name in localized wiki", it, "Catégorie:Capitale", Link{Kind: ArticleKind, Title: "Catégorie:Capitale"}},
+	}
+
+	for _, c := range cases {
+		if got := c.ns.ClassifyLink(c.target); got != c.want {
+			t.Errorf("%s: ClassifyLink(%q) = %+v, want %+v", c.name, c.target, got, c.want)
+		}
+	}
+}
+
+func TestLocalizedNamespaces(t *testing.T) {
+	var si reader.SiteInfo
+	err := xml.Unmarshal([]byte(itSiteInfo), &si)
+	if err != nil {
+		t.Fatalf("Unmarshal siteinfo: %s", err)
+	}
+	it := NewNamespaces(&si)
+
+	nodes := Parse("[[Roma]] è la capitale.[[Categoria:Capitali europee|Roma]]{{Template:Infobox città|nome=Roma}}")
+
+	if got := Plaintext(nodes, nil, it); got != "Roma è la capitale." {
+		t.Errorf("Plaintext = %q, want %q", got, "Roma è la capitale.")
+	}
+
+	categories := Categories(nodes, it)
+	if len(categories) != 1 || categories[0].Name != "Capitali europee" || categories[0].SortKey != "Roma" {
+		t.Errorf("Categories = %+v, want [{Capitali europee Roma}]", categories)
+	}
+
+	if name := FirstInfobox(nodes, it); name != "Template:Infobox città" {
+		t.Errorf("FirstInfobox = %q, want %q", name, "Template:Infobox città")
+	}
+}
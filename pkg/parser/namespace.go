@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// Namespace keys, identical in every wiki
+const (
+	MediaNamespace     = -2
+	SpecialNamespace   = -1
+	MainNamespace      = 0
+	TalkNamespace      = 1
+	UserNamespace      = 2
+	ProjectNamespace   = 4
+	FileNamespace      = 6
+	MediaWikiNamespace = 8
+	TemplateNamespace  = 10
+	HelpNamespace      = 12
+	CategoryNamespace  = 14
+	PortalNamespace    = 100
+	DraftNamespace     = 118
+	ModuleNamespace    = 828
+)
+
+// canonicalNamespaces are english namespace names and aliases, valid in every wiki
+var canonicalNamespaces = map[string]int{
+	"media": MediaNamespace, "special": SpecialNamespace, "talk": TalkNamespace, "user": UserNamespace,
+	"user talk": 3, "project": ProjectNamespace, "project talk": 5, "file": FileNamespace, "image": FileNamespace,
+	"file talk": 7, "image talk": 7, "mediawiki": MediaWikiNamespace, "mediawiki talk": 9,
+	"template": TemplateNamespace, "template talk": 11, "help": HelpNamespace, "help talk": 13,
+	"category": CategoryNamespace, "category talk": 15, "portal": PortalNamespace, "portal talk": 101,
+	"draft": DraftNamespace, "draft talk": 119, "module": ModuleNamespace, "module talk": 829,
+	"wikipedia": ProjectNamespace, "wp": ProjectNamespace, "wikipedia talk": 5,
+}
+
+// DefaultNamespaces knows canonical names and localized names of main languages, used when siteinfo isn't available
+var DefaultNamespaces = newNamespaces(map[string]int{
+	// fr
+	"média": MediaNamespace, "spécial": SpecialNamespace, "discussion": TalkNamespace, "utilisateur": UserNamespace,
+	"wikipédia": ProjectNamespace, "fichier": FileNamespace, "modèle": TemplateNamespace, "aide": HelpNamespace,
+	"catégorie": CategoryNamespace, "portail": PortalNamespace, "projet": 102, "référence": 104,
+	// de
+	"medium": MediaNamespace, "spezial": SpecialNamespace, "diskussion": TalkNamespace, "benutzer": UserNamespace,
+	"datei": FileNamespace, "bild": FileNamespace, "vorlage": TemplateNamespace, "hilfe": HelpNamespace,
+	"kategorie": CategoryNamespace, "modul": ModuleNamespace,
+	// es
+	"archivo": FileNamespace, "imagen": FileNamespace, "plantilla": TemplateNamespace, "ayuda": HelpNamespace,
+	"categoría": CategoryNamespace, "anexo": 104, "portal": PortalNamespace,
+})
+
+// Namespaces maps namespace names of a wiki to their key. Case is the wiki title case, used to normalize link targets.
+type Namespaces struct {
+	Case title.Case
+
+	keys map[string]int
+}
+
+func newNamespaces(local map[string]int) *Namespaces {
+	ns := &Namespaces{keys: make(map[string]int)}
+	for name, key := range canonicalNamespaces {
+		ns.keys[name] = key
+	}
+	for name, key := range local {
+		ns.keys[name] = key
+	}
+	return ns
+}
+
+// NewNamespaces returns namespaces declared in dump siteinfo, along with canonical english names.
+// DefaultNamespaces is returned if siteinfo has no namespace.
+func NewNamespaces(si *reader.SiteInfo) *Namespaces {
+	if si == nil || len(si.Namespaces) == 0 {
+		return DefaultNamespaces
+	}
+
+	local := make(map[string]int)
+	for _, n := range si.Namespaces {
+		if n.Name != "" {
+			local[normalizeNamespace(n.Name)] = n.Key
+		}
+	}
+	ns := newNamespaces(local)
+	ns.Case = title.ParseCase(si.Case)
+	return ns
+}
+
+// Split returns namespace key of title and title without namespace prefix. Titles without known prefix are in
+// main namespace and returned unchanged.
+func (ns *Namespaces) Split(title string) (int, string) {
+	t := strings.SplitN(title, ":", 2)
+	if len(t) != 2 {
+		return MainNamespace, title
+	}
+
+	key, ok := ns.keys[normalizeNamespace(t[0])]
+	if !ok {
+		return MainNamespace, title
+	}
+	return key, strings.TrimSpace(t[1])
+}
+
+// Is returns true if title is in namespace key
+func (ns *Namespaces) Is(title string, key int) bool {
+	k, _ := ns.Split(title)
+	return k == key
+}
+
+// TemplateName normalizes transcluded template name for comparison: lowercased, Template namespace prefix removed
+func (ns *Namespaces) TemplateName(name string) string {
+	name = strings.TrimSpace(strings.Replace(name, "_", " ", -1))
+	if key, rest := ns.Split(name); key == TemplateNamespace {
+		name = rest
+	}
+	return strings.ToLower(name)
+}
+
+func normalizeNamespace(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Replace(name, "_", " ", -1)))
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Nature is page content nature, stored in page_nature.nature. Pages are classified by pkg/classifier rules.
+type Nature int
+
+const (
+	UnknownNature Nature = iota
+	ListNature
+	LanguageNature
+	HumanNature
+	PlaceNature
+)
+
+var natureNames = []string{"unknown", "list", "language", "human", "place"}
+
+func (n Nature) String() string {
+	if n < 0 || int(n) >= len(natureNames) {
+		return natureNames[UnknownNature]
+	}
+	return natureNames[n]
+}
+
+// ParseNature returns Nature named name, such as 'human'
+func ParseNature(name string) (Nature, error) {
+	for i, n := range natureNames {
+		if strings.EqualFold(n, name) {
+			return Nature(i), nil
+		}
+	}
+	return UnknownNature, fmt.Errorf("unknown nature '%s'", name)
+}
+
+// FirstInfobox returns name of first infobox template of parsed page, empty if there is none
+func FirstInfobox(nodes []Node, ns *Namespaces) string {
+	var name string
+	Walk(nodes, func(n *Node) bool {
+		if name != "" {
+			return false
+		}
+		if n.Type == TemplateNode && IsInfobox(n, ns) {
+			name = strings.TrimSpace(n.Name)
+			return false
+		}
+		return true
+	})
+	return name
+}
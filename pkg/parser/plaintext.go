@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// DefaultPlaintextTemplates lists templates kept by Plaintext when none are given, every other template being stripped.
+// Names ending with '*' match any template starting with the prefix.
+var DefaultPlaintextTemplates = []string{"lang", "lang-*", "langue", "convert", "unité", "nowrap", "nobr"}
+
+var (
+	quotesRe     = regexp.MustCompile(`''+`)
+	magicWordsRe = regexp.MustCompile(`__[A-Z]+__`)
+	emptyParenRe = regexp.MustCompile(` ?\([\s,;]*\)`)
+)
+
+// Plaintext renders parsed wikitext as readable text: links are replaced by their label, references, tables, files and
+// templates are dropped, except templates listed in templates. Paragraphs and sections are separated by a blank line.
+// File and category links are recognized from ns.
+func Plaintext(nodes []Node, templates []string, ns *Namespaces) string {
+	r := &plaintextRenderer{templates: templates, ns: ns}
+	r.render(nodes)
+	return cleanupPlaintext(r.b.String())
+}
+
+type plaintextRenderer struct {
+	b         strings.Builder
+	templates []string
+	ns        *Namespaces
+}
+
+func (r *plaintextRenderer) render(nodes []Node) {
+	for i := range nodes {
+		n := &nodes[i]
+
+		switch n.Type {
+		case TextNode:
+			r.b.WriteString(n.Text)
+		case LinkNode:
+			r.link(n)
+		case ExternalLinkNode:
+			// links without label are displayed as a number, which carries no meaning
+			r.render(n.Children)
+		case TemplateNode:
+			r.template(n)
+		case HeadingNode:
+			r.b.WriteString("\n\n")
+			r.render(n.Children)
+			r.b.WriteString("\n\n")
+		case TagNode:
+			r.tag(n)
+		}
+	}
+}
+
+func (r *plaintextRenderer) link(n *Node) {
+	target := n.Target
+	if _, ok := langLink(n); ok {
+		return
+	}
+
+	if !strings.HasPrefix(target, ":") {
+		switch key, _ := r.ns.Split(target); key {
+		case FileNamespace, MediaNamespace, CategoryNamespace:
+			return
+		}
+	}
+	target = strings.TrimPrefix(target, ":")
+
+	label := n.Label()
+	if len(label) > 0 && strings.TrimSpace(Text(label)) != "" {
+		r.render(label)
+		return
+	}
+	r.b.WriteString(target)
+}
+
+func (r *plaintextRenderer) template(n *Node) {
+	name := r.ns.TemplateName(n.Name)
+
+	// formatnum magic word displays its argument, as in {{formatnum:2165423}}
+	if strings.HasPrefix(name, "formatnum:") {
+		r.b.WriteString(strings.TrimSpace(n.Name[strings.Index(n.Name, ":")+1:]))
+		return
+	}
+
+	if !r.keeps(name) {
+		return
+	}
+
+	switch name {
+	case "convert":
+		r.convert(n)
+		return
+	case "unité":
+		r.positional(n)
+		return
+	}
+
+	// kept templates usually wrap their text in last positional parameter, such as {{lang|fr|texte}}
+	for i := len(n.Params) - 1; i >= 0; i-- {
+		if !n.Params[i].Named {
+			r.render(n.Params[i].Value)
+			return
+		}
+	}
+}
+
+func (r *plaintextRenderer) keeps(name string) bool {
+	for _, t := range r.templates {
+		t = r.ns.TemplateName(t)
+		if strings.HasSuffix(t, "*") && strings.HasPrefix(name, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *plaintextRenderer) tag(n *Node) {
+	switch n.Name {
+	case "ref", "references", "includeonly", "table", "gallery":
+		return
+	case "br":
+		r.b.WriteString("\n")
+		return
+	case "nowiki", "pre":
+		r.b.WriteString(n.Text)
+		return
+	}
+
+	// remaining raw tags such as math or timeline aren't text
+	if rawTags[n.Name] {
+		return
+	}
+
+	r.render(n.Children)
+}
+
+// convert renders {{convert|10|km|mi}} as "10 km" and {{convert|10|to|20|km}} as "10 to 20 km"
+func (r *plaintextRenderer) convert(n *Node) {
+	var values []string
+	for _, p := range n.Params {
+		if !p.Named {
+			values = append(values, strings.TrimSpace(Plaintext(p.Value, r.templates, r.ns)))
+		}
+	}
+
+	count := 2
+	if len(values) >= 4 {
+		switch values[1] {
+		case "to", "-", "–", "and", "or", "x", "by", "+", "and(-)", "to(-)":
+			count = 4
+		}
+	}
+	if len(values) < count {
+		count = len(values)
+	}
+	r.b.WriteString(strings.Join(values[:count], " "))
+}
+
+// positional renders every positional parameter separated by a space
+func (r *plaintextRenderer) positional(n *Node) {
+	var values []string
+	for _, p := range n.Params {
+		if !p.Named {
+			values = append(values, strings.TrimSpace(Plaintext(p.Value, r.templates, r.ns)))
+		}
+	}
+	r.b.WriteString(strings.Join(values, " "))
+}
+
+// cleanupPlaintext removes formatting markup left in text, collapses spaces and keeps at most one blank line between paragraphs
+func cleanupPlaintext(s string) string {
+	s = quotesRe.ReplaceAllString(s, "")
+	s = magicWordsRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	var lines []string
+	blank := true
+	for _, line := range strings.Split(s, "\n") {
+		// list and indentation markers
+		line = strings.TrimLeft(line, "*#:; \t")
+		if strings.HasPrefix(line, "----") {
+			line = ""
+		}
+		line = strings.Join(strings.Fields(line), " ")
+		// parentheses left empty by stripped templates, such as pronunciation
+		line = emptyParenRe.ReplaceAllString(line, "")
+
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+
+		lines = append(lines, line)
+		blank = false
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
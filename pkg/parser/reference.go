@@ -1,80 +1,74 @@
 package parser
 
 import (
-	"regexp"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
 )
 
+// Reference is an article link. Title is normalized, see title.Normalize.
 type Reference struct {
 	ID        int
 	Title     string
 	Occurence int
 	Index     int
+
+	// Section is the ordinal of section where first occurence appears, see Sections
+	Section int
+
+	// Trailing is set if first occurence is in a trailing section such as See also, see TrailingSections
+	Trailing bool
+
+	// Anchor is the section anchor of first occurence linking to a section, as in [[France#History]]
+	Anchor string
+
+	// Surfaces counts text displayed by occurences: link label, or target as written if link has none
+	Surfaces map[string]int
+
+	// Offsets are byte offsets of occurences in wikitext, in page order, see CharOffsets
+	Offsets []int
 }
 
+// Cleanup returns case insensitive title of link target s, without brackets, label nor anchor, see title.Fold.
+// Colons are kept, as in 'star wars: episode iv', namespace prefixes being handled by Namespaces.ClassifyLink.
 func Cleanup(s string) string {
 	s = strings.TrimPrefix(s, "[[")
 	s = strings.TrimSuffix(s, "]]")
 
 	s = strings.Split(s, "|")[0]
 	s = strings.Split(s, "#")[0]
+	s = strings.TrimPrefix(strings.TrimSpace(s), ":")
 
-	t := strings.Split(s, ":")
-	if len(t) != 1 {
-		s = t[1]
-	}
-
-	s = strings.TrimLeft(s, " ")
-	s = strings.TrimRight(s, " ")
-
-	s = strings.Replace(s, "_", " ", -1)
-
-	s = strings.ToLower(s)
-
-	return s
+	return title.Fold(s)
 }
 
-func PageReferences(p *reader.Page) map[string]*Reference {
-	c := p.Text
-
-	// Try to stop before '==See also=='
-	i := strings.Index(c, "==See also==")
-	if i > 0 {
-		//fmt.Printf("Found ==See also== (%d)!\n", i)
-		c = c[:i]
-	}
-	i = strings.Index(c, "== See also ==")
-	if i > 0 {
-		//fmt.Printf("Found == See also == (%d)!\n", i)
-		c = c[:i]
-	}
-
-	// TODO: should be in config
-	var ignoredPrefixes = []string{
-		"wikipedia", "template", "project", "portal", "category", "draft", "module", "list",
-		"wikipédia", "modèle", "projet", "portail", "catégorie", "ébauche", "module", "liste",
-	}
-
-	re := regexp.MustCompile(`\[\[.(.*?)\]\]`)
-	sub := re.FindAllString(c, -1)
+func PageReferences(p *reader.Page, trailing []string, ns *Namespaces) map[string]*Reference {
+	return References(Parse(p.Text), trailing, ns)
+}
 
-	var index int
+// References extracts article links of parsed page, including links nested in templates, tags and captions.
+// Links to files, categories, other namespaces, interwikis and other languages are skipped, see ClassifyLink.
+// References first found after a heading listed in trailing are flagged as Trailing.
+func References(nodes []Node, trailing []string, ns *Namespaces) map[string]*Reference {
+	var i, index, section int
+	trailingStart := TrailingStart(nodes, trailing, ns)
 	references := make(map[string]*Reference)
-	for _, s := range sub {
-
-		// do not insert wikipedia meta page
-		for _, prefix := range ignoredPrefixes {
-			if strings.HasPrefix(strings.ToLower(s), prefix+":") {
-				continue
-			}
+	extract := func(n *Node) bool {
+		if n.Type != LinkNode {
+			return true
 		}
 
-		s = Cleanup(s)
+		l := ns.ClassifyLink(n.Target)
+		if l.Kind != ArticleKind {
+			return true
+		}
+		s := title.Normalize(l.Title, ns.Case)
 
 		if s == "" {
-			continue
+			return true
 		}
 
 		ref, ok := references[s]
@@ -82,89 +76,74 @@ func PageReferences(p *reader.Page) map[string]*Reference {
 			ref.Occurence++
 		} else {
 			index++
-			references[s] = &Reference{
+			ref = &Reference{
 				Title:     s,
 				Occurence: 1,
 				Index:     index,
+				Section:   section,
+				Trailing:  i >= trailingStart,
+				Surfaces:  make(map[string]int),
 			}
+			references[s] = ref
 		}
-	}
-	return references
-}
 
-func IsList(p *reader.Page) bool {
-	if strings.HasPrefix(strings.ToLower(p.Title), "list") {
+		if ref.Anchor == "" {
+			ref.Anchor = l.Anchor
+		}
+		if surface := linkSurface(n, ns); surface != "" {
+			ref.Surfaces[surface]++
+		}
+		ref.Offsets = append(ref.Offsets, n.Pos)
 		return true
 	}
 
-	return false
-}
-
-func IsLanguage(p *reader.Page) bool {
-	d := p.Text
-
-	re := regexp.MustCompile(`{{Infobox(.*?)language`)
-	if re.MatchString(d) {
-		return true
+	for i = range nodes {
+		if nodes[i].Type == HeadingNode {
+			section++
+		}
+		Walk(nodes[i:i+1], extract)
 	}
-
-	return false
+	return references
 }
 
-func IsHuman(p *reader.Page) bool {
-	d := p.Text
-
-	re := regexp.MustCompile(`{{Infobox(.*?)scientist`)
-	if re.MatchString(d) {
-		return true
+// linkSurface returns text displayed by link n
+func linkSurface(n *Node, ns *Namespaces) string {
+	if label := n.Label(); len(label) > 0 {
+		if surface := strings.TrimSpace(Plaintext(label, nil, ns)); surface != "" {
+			return surface
+		}
 	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(n.Target), ":"))
+}
 
-	re = regexp.MustCompile(`{{Infobox(.*?)artist`)
-	if re.MatchString(d) {
-		return true
+// CharOffsets converts byte offsets of text into character offsets
+func CharOffsets(text string, offsets []int) []int {
+	sorted := make([]int, len(offsets))
+	copy(sorted, offsets)
+	sort.Ints(sorted)
+
+	chars := make(map[int]int, len(offsets))
+	var last, count int
+	for _, o := range sorted {
+		if o > len(text) {
+			o = len(text)
+		}
+		count += utf8.RuneCountInString(text[last:o])
+		chars[o], last = count, o
 	}
 
-	if strings.Contains(d, "| birth_date") || strings.Contains(d, "|birth_date") {
-		return true
+	result := make([]int, len(offsets))
+	for i, o := range offsets {
+		if o > len(text) {
+			o = len(text)
+		}
+		result[i] = chars[o]
 	}
-
-	return false
+	return result
 }
 
-func IsPlace(p *reader.Page) bool {
-	d := p.Text
-
-	re := regexp.MustCompile(`{{Infobox(.*?)commune`)
-	if re.MatchString(d) {
-		return true
-	}
-	re = regexp.MustCompile(`{{Infobox(.*?)town`)
-	if re.MatchString(d) {
-		return true
-	}
-	re = regexp.MustCompile(`{{Infobox(.*?)country`)
-	if re.MatchString(d) {
-		return true
-	}
-	re = regexp.MustCompile(`{{Infobox(.*?)state`)
-	if re.MatchString(d) {
-		return true
-	}
-	re = regexp.MustCompile(`{{Infobox(.*?)settlement`)
-	if re.MatchString(d) {
-		return true
-	}
-	/*
-		if strings.Contains(p.Revisions[0].Content, "|coordinate") ||
-			strings.Contains(p.Revisions[0].Content, "|Coordinate") ||
-			strings.Contains(p.Revisions[0].Content, "| coordinate") ||
-			strings.Contains(p.Revisions[0].Content, "| Coordinate") ||
-			strings.Contains(p.Revisions[0].Content, "|Latitude") ||
-			strings.Contains(p.Revisions[0].Content, "|latitude") ||
-			strings.Contains(p.Revisions[0].Content, "| latitude") ||
-			strings.Contains(p.Revisions[0].Content, "| Latitude") {
-			return true
-		}
-	*/
-	return false
+// IsMeta returns true for wikipedia meta pages (templates, categories, portals...), which are not articles, from
+// page namespace declared in dump
+func IsMeta(p *reader.Page) bool {
+	return p.Ns != MainNamespace
 }
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Section is a part of page delimited by headings. Lead section, before first heading, has ordinal and level 0.
+// Parent is the ordinal of the enclosing section, -1 for lead section.
+type Section struct {
+	Ordinal int
+	Parent  int
+	Level   int
+	Title   string
+	Anchor  string
+
+	// Nodes holds section body, without heading nor subsections
+	Nodes []Node
+}
+
+// Text renders section body as plain text, keeping given templates
+func (s *Section) Text(templates []string, ns *Namespaces) string {
+	return Plaintext(s.Nodes, templates, ns)
+}
+
+// Sections splits parsed page into sections, in page order. Lead section is always returned, even if empty.
+func Sections(nodes []Node, ns *Namespaces) []Section {
+	sections := []Section{{Parent: -1}}
+	anchors := make(map[string]int)
+
+	// ordinals of currently open sections, indexed by level
+	var open [7]int
+
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Type != HeadingNode {
+			last := &sections[len(sections)-1]
+			last.Nodes = append(last.Nodes, *n)
+			continue
+		}
+
+		s := Section{
+			Ordinal: len(sections),
+			Level:   n.Level,
+			Title:   HeadingTitle(n, ns),
+		}
+
+		// parent is the closest open section of lower level
+		for l := n.Level - 1; l >= 0; l-- {
+			if open[l] > 0 || l == 0 {
+				s.Parent = open[l]
+				break
+			}
+		}
+		open[n.Level] = s.Ordinal
+		for l := n.Level + 1; l < len(open); l++ {
+			open[l] = 0
+		}
+
+		s.Anchor = Anchor(s.Title)
+		anchors[s.Anchor]++
+		if c := anchors[s.Anchor]; c > 1 {
+			s.Anchor = fmt.Sprintf("%s_%d", s.Anchor, c)
+		}
+
+		sections = append(sections, s)
+	}
+
+	return sections
+}
+
+// HeadingTitle returns heading n title as displayed
+func HeadingTitle(n *Node, ns *Namespaces) string {
+	return strings.TrimSpace(Plaintext(n.Children, nil, ns))
+}
+
+// Anchor returns section anchor of title, as used in page url fragment
+func Anchor(title string) string {
+	return strings.Replace(strings.TrimSpace(title), " ", "_", -1)
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/reader"
+)
+
+// Stats are size metrics of a page, used to filter stubs or rank pages
+type Stats struct {
+	// Bytes is the size of wikitext
+	Bytes int
+	// Words counts words of plain text rendering, see Plaintext
+	Words int
+	// Sections counts headings, lead section excluded
+	Sections int
+	// InternalLinks counts distinct pages linked, see References
+	InternalLinks int
+	// ExternalLinks counts distinct urls linked, see ExternalLinks
+	ExternalLinks int
+	// Citations counts footnotes and citation templates outside footnotes
+	Citations int
+	// Images counts embedded files, in links and galleries
+	Images int
+}
+
+// PageStats computes metrics of page p parsed as nodes. Plain text is rendered keeping templates.
+func PageStats(p *reader.Page, nodes []Node, ns *Namespaces, templates []string) Stats {
+	s := Stats{
+		Bytes:         len(p.Text),
+		Words:         len(strings.Fields(Plaintext(nodes, templates, ns))),
+		InternalLinks: len(References(nodes, nil, ns)),
+		ExternalLinks: len(ExternalLinks(nodes, ns)),
+	}
+
+	for i := range nodes {
+		if nodes[i].Type == HeadingNode {
+			s.Sections++
+		}
+	}
+
+	Walk(nodes, func(n *Node) bool {
+		switch n.Type {
+		case CommentNode:
+			return false
+		case LinkNode:
+			l := ns.ClassifyLink(n.Target)
+			if l.Kind == FileKind && l.Namespace == FileNamespace && !l.Inline {
+				s.Images++
+			}
+		case TemplateNode:
+			if IsCitation(n, ns) {
+				s.Citations++
+				return false
+			}
+		case TagNode:
+			switch n.Name {
+			case "ref":
+				// named footnotes reused as <ref name="x" /> are counted once
+				if len(n.Children) > 0 {
+					s.Citations++
+				}
+				return false
+			case "gallery":
+				s.Images += galleryFiles(n.Text)
+			}
+		}
+		return true
+	})
+
+	return s
+}
+
+// galleryFiles counts files of <gallery> content, one per non empty line
+func galleryFiles(text string) int {
+	var count int
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Transclusion is a template used by a page, with its parameters as wikitext
+type Transclusion struct {
+	Name   string
+	Params []TemplateParam
+}
+
+// TemplateParam is a transclusion parameter, positional parameters being named after their position
+type TemplateParam struct {
+	Name  string
+	Value string
+}
+
+// magicVariables are variables looking like templates, such as {{PAGENAME}}
+var magicVariables = map[string]bool{
+	"!": true, "=": true, "pagename": true, "fullpagename": true, "basepagename": true, "subpagename": true,
+	"namespace": true, "talkpagename": true, "sitename": true, "server": true, "servername": true,
+	"currentyear": true, "currentmonth": true, "currentday": true, "currenttime": true, "localyear": true,
+	"revisionid": true, "revisionyear": true, "numberofarticles": true, "pagenamee": true, "fullpagenamee": true,
+	"toc": true,
+}
+
+// Templates returns templates transcluded by parsed page, nested ones included, in page order. Parser functions
+// ({{#if:}}) and magic words ({{DEFAULTSORT:}}, {{PAGENAME}}) are skipped. Names are normalized with namespaces
+// ns: Template prefix removed, underscores replaced and first letter uppercased.
+func Templates(nodes []Node, ns *Namespaces) []Transclusion {
+	var transclusions []Transclusion
+
+	Walk(nodes, func(n *Node) bool {
+		if n.Type != TemplateNode {
+			return true
+		}
+
+		name, ok := transclusionName(n.Name, ns)
+		if !ok {
+			return true
+		}
+
+		t := Transclusion{Name: name}
+		for _, p := range n.Params {
+			t.Params = append(t.Params, TemplateParam{Name: strings.TrimSpace(p.Name), Value: strings.TrimSpace(rawValue(p.Value))})
+		}
+		transclusions = append(transclusions, t)
+		return true
+	})
+
+	return transclusions
+}
+
+// transclusionName returns normalized template name, ok being false for parser functions and magic words
+func transclusionName(name string, ns *Namespaces) (string, bool) {
+	name = strings.Join(strings.Fields(strings.Replace(name, "_", " ", -1)), " ")
+	if name == "" || strings.HasPrefix(name, "#") || magicVariables[strings.ToLower(name)] {
+		return "", false
+	}
+
+	if strings.Contains(name, ":") {
+		key, rest := ns.Split(name)
+		switch {
+		case key == TemplateNamespace:
+			name = rest
+		case key == MainNamespace && !strings.HasPrefix(name, ":"):
+			// not a namespace, such as {{DEFAULTSORT:Paris}} or {{formatnum:123}}
+			return "", false
+		}
+	}
+
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:], true
+}
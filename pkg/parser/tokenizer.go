@@ -0,0 +1,374 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+type TokenType int
+
+const (
+	TextToken TokenType = iota
+	NewlineToken
+	PipeToken
+	EqualsToken
+	LinkOpenToken          // [[
+	LinkCloseToken         // ]]
+	TemplateOpenToken      // {{
+	TemplateCloseToken     // }}
+	ExternalLinkOpenToken  // [ followed by an url, Target holds the url
+	ExternalLinkCloseToken // ]
+	HeadingOpenToken       // = at line start, Level holds heading level
+	HeadingCloseToken      // = at line end
+	TableOpenToken         // {| at line start
+	TableCloseToken        // |} at line start
+	TagOpenToken           // <tag attrs>
+	TagCloseToken          // </tag>
+	TagSelfClosingToken    // <tag /> and void tags such as <br>
+	RawTagToken            // <nowiki>...</nowiki> and other tags whose content isn't wikitext, Content holds it
+	CommentToken           // <!-- -->, Content holds comment
+)
+
+// Token is a lexical unit of wikitext. Raw is the source text of the token, starting at byte offset Pos.
+type Token struct {
+	Type    TokenType
+	Pos     int
+	Raw     string
+	Name    string
+	Attrs   string
+	Target  string
+	Content string
+	Level   int
+}
+
+// tags lists HTML and extension tags recognized as such, other tags being kept as text
+var tags = map[string]bool{
+	"abbr": true, "b": true, "bdi": true, "big": true, "blockquote": true, "br": true, "categorytree": true,
+	"ce": true, "center": true, "chem": true, "cite": true, "code": true, "dd": true, "del": true, "div": true,
+	"dl": true, "dt": true, "em": true, "font": true, "gallery": true, "graph": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "hiero": true, "hr": true, "i": true, "imagemap": true,
+	"includeonly": true, "indicator": true, "inputbox": true, "ins": true, "kbd": true, "li": true,
+	"mapframe": true, "mark": true, "math": true, "noinclude": true, "nowiki": true, "ol": true,
+	"onlyinclude": true, "p": true, "poem": true, "pre": true, "q": true, "ref": true, "references": true,
+	"rp": true, "rt": true, "ruby": true, "s": true, "samp": true, "score": true, "section": true, "small": true,
+	"source": true, "span": true, "strike": true, "strong": true, "sub": true, "sup": true,
+	"syntaxhighlight": true, "table": true, "td": true, "templatedata": true, "th": true, "time": true,
+	"timeline": true, "tr": true, "tt": true, "u": true, "ul": true, "var": true, "wbr": true,
+}
+
+// rawTags content is not wikitext and is kept unparsed
+var rawTags = map[string]bool{
+	"nowiki": true, "pre": true, "math": true, "chem": true, "ce": true, "syntaxhighlight": true,
+	"source": true, "score": true, "timeline": true, "graph": true, "templatedata": true, "hiero": true,
+	"mapframe": true, "imagemap": true, "gallery": true, "categorytree": true, "inputbox": true,
+}
+
+// voidTags never have content nor closing tag
+var voidTags = map[string]bool{
+	"br": true, "hr": true, "wbr": true,
+}
+
+var (
+	tagRe        = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)([^<>]*?)(/?)>`)
+	urlSchemeRe  = regexp.MustCompile(`(?i)^(https?://|ftps?://|//|mailto:|news:|irc://|ircs://|gopher://|telnet://)`)
+	specialChars = "[]{}|=<\n"
+)
+
+// Tokenizer splits wikitext into tokens, one at a time
+type Tokenizer struct {
+	src string
+	pos int
+
+	// position of closing '=' run and end of current heading line, -1 outside of headings
+	headingClose int
+	headingEnd   int
+
+	// set once line indentation is emitted, so table delimiters are still recognized
+	indented bool
+}
+
+func NewTokenizer(src string) *Tokenizer {
+	return &Tokenizer{
+		src:          src,
+		headingClose: -1,
+		headingEnd:   -1,
+	}
+}
+
+// Next returns next token, false once source is consumed
+func (t *Tokenizer) Next() (Token, bool) {
+	if t.pos >= len(t.src) {
+		return Token{}, false
+	}
+
+	if t.pos == t.headingClose {
+		return t.closeHeading(), true
+	}
+
+	if t.atLineStart() || t.indented {
+		tok, ok := t.lineStart()
+		if ok {
+			return tok, true
+		}
+	}
+
+	s := t.src[t.pos:]
+	switch s[0] {
+	case '\n':
+		return t.emit(NewlineToken, 1), true
+	case '|':
+		return t.emit(PipeToken, 1), true
+	case '=':
+		return t.emit(EqualsToken, 1), true
+	case '[':
+		if strings.HasPrefix(s, "[[") {
+			return t.emit(LinkOpenToken, 2), true
+		}
+		if url := externalURL(s[1:]); url != "" {
+			tok := t.emit(ExternalLinkOpenToken, 1+len(url))
+			tok.Target = url
+			return tok, true
+		}
+	case ']':
+		if strings.HasPrefix(s, "]]") {
+			return t.emit(LinkCloseToken, 2), true
+		}
+		return t.emit(ExternalLinkCloseToken, 1), true
+	case '{':
+		if strings.HasPrefix(s, "{{") {
+			return t.emit(TemplateOpenToken, 2), true
+		}
+	case '}':
+		if strings.HasPrefix(s, "}}") {
+			return t.emit(TemplateCloseToken, 2), true
+		}
+	case '<':
+		tok, ok := t.tag()
+		if ok {
+			return tok, true
+		}
+	}
+
+	return t.text(), true
+}
+
+func (t *Tokenizer) emit(typ TokenType, n int) Token {
+	tok := Token{Type: typ, Pos: t.pos, Raw: t.src[t.pos : t.pos+n]}
+	t.pos += n
+	return tok
+}
+
+func (t *Tokenizer) atLineStart() bool {
+	return t.pos == 0 || t.src[t.pos-1] == '\n'
+}
+
+// text consumes text up to next character which may start a token
+func (t *Tokenizer) text() Token {
+	start := t.pos
+	end := len(t.src)
+	if t.headingClose > start {
+		end = t.headingClose
+	}
+
+	// first character is always consumed, it either isn't special or didn't start a token
+	i := strings.IndexAny(t.src[start+1:end], specialChars)
+	if i < 0 {
+		t.pos = end
+	} else {
+		t.pos = start + 1 + i
+	}
+
+	return Token{Type: TextToken, Pos: start, Raw: t.src[start:t.pos]}
+}
+
+// lineStart handles tokens only valid at line start: headings and table delimiters
+func (t *Tokenizer) lineStart() (Token, bool) {
+	s := t.src[t.pos:]
+	indented := t.indented
+	t.indented = false
+
+	if s[0] == '=' && !indented {
+		return t.openHeading()
+	}
+
+	// tables can be indented with spaces or ':', emit indentation first and delimiter on next call
+	trimmed := strings.TrimLeft(s, " \t:")
+	if strings.HasPrefix(trimmed, "{|") || strings.HasPrefix(trimmed, "|}") {
+		if n := len(s) - len(trimmed); n > 0 {
+			t.indented = true
+			return t.emit(TextToken, n), true
+		}
+	}
+	if strings.HasPrefix(s, "{|") {
+		return t.emit(TableOpenToken, 2), true
+	}
+	if strings.HasPrefix(s, "|}") {
+		return t.emit(TableCloseToken, 2), true
+	}
+
+	return Token{}, false
+}
+
+func (t *Tokenizer) openHeading() (Token, bool) {
+	eol := strings.IndexByte(t.src[t.pos:], '\n')
+	if eol < 0 {
+		eol = len(t.src)
+	} else {
+		eol += t.pos
+	}
+
+	// trailing spaces and comments are allowed after closing '='
+	end := eol
+	for {
+		line := strings.TrimRight(t.src[t.pos:end], " \t")
+		end = t.pos + len(line)
+		if !strings.HasSuffix(line, "-->") {
+			break
+		}
+		i := strings.LastIndex(line, "<!--")
+		if i < 0 {
+			break
+		}
+		end = t.pos + i
+	}
+
+	line := t.src[t.pos:end]
+	lead := len(line) - len(strings.TrimLeft(line, "="))
+	trail := len(line) - len(strings.TrimRight(line, "="))
+	if lead == len(line) {
+		// line made only of '=', which is a heading only if title can be made of '=' in the middle
+		lead = len(line) / 2
+		trail = len(line) - lead
+		if lead == trail {
+			lead--
+			trail--
+		}
+	}
+
+	level := lead
+	if trail < level {
+		level = trail
+	}
+	if level > 6 {
+		level = 6
+	}
+	if level < 1 || 2*level >= len(line) {
+		return Token{}, false
+	}
+
+	t.headingClose = end - level
+	t.headingEnd = end
+	tok := t.emit(HeadingOpenToken, level)
+	tok.Level = level
+	return tok, true
+}
+
+func (t *Tokenizer) closeHeading() Token {
+	tok := Token{Type: HeadingCloseToken, Pos: t.pos, Raw: t.src[t.pos:t.headingEnd], Level: t.headingEnd - t.pos}
+	t.pos = t.headingEnd
+	t.headingClose, t.headingEnd = -1, -1
+	return tok
+}
+
+func (t *Tokenizer) tag() (Token, bool) {
+	s := t.src[t.pos:]
+
+	if strings.HasPrefix(s, "<!--") {
+		end := strings.Index(s[4:], "-->")
+		n := len(s)
+		content := s[4:]
+		if end >= 0 {
+			n = 4 + end + 3
+			content = s[4 : 4+end]
+		}
+		if t.headingClose > t.pos && t.pos+n > t.headingClose {
+			// comment spans over heading end, which can't be
+			return Token{}, false
+		}
+		tok := t.emit(CommentToken, n)
+		tok.Content = content
+		return tok, true
+	}
+
+	m := tagRe.FindStringSubmatch(s)
+	if m == nil {
+		return Token{}, false
+	}
+	name := strings.ToLower(m[2])
+	if !tags[name] {
+		return Token{}, false
+	}
+	if t.headingClose > t.pos && t.pos+len(m[0]) > t.headingClose {
+		return Token{}, false
+	}
+
+	if m[1] == "/" {
+		tok := t.emit(TagCloseToken, len(m[0]))
+		tok.Name = name
+		return tok, true
+	}
+
+	attrs := strings.TrimSpace(m[3])
+	if m[4] == "/" || voidTags[name] {
+		tok := t.emit(TagSelfClosingToken, len(m[0]))
+		tok.Name = name
+		tok.Attrs = attrs
+		return tok, true
+	}
+
+	if rawTags[name] {
+		start := len(m[0])
+		end, closeLen := indexCloseTag(s[start:], name)
+		if end < 0 {
+			// unclosed extension tag is rendered as text
+			return Token{}, false
+		}
+		if t.headingClose > t.pos && t.pos+start+end+closeLen > t.headingClose {
+			return Token{}, false
+		}
+		tok := t.emit(RawTagToken, start+end+closeLen)
+		tok.Name = name
+		tok.Attrs = attrs
+		tok.Content = s[start : start+end]
+		return tok, true
+	}
+
+	tok := t.emit(TagOpenToken, len(m[0]))
+	tok.Name = name
+	tok.Attrs = attrs
+	return tok, true
+}
+
+// indexCloseTag returns position and length of </name> in s, case insensitive
+func indexCloseTag(s string, name string) (int, int) {
+	offset := 0
+	for {
+		i := strings.Index(s[offset:], "</")
+		if i < 0 {
+			return -1, 0
+		}
+		i += offset
+
+		rest := s[i+2:]
+		if len(rest) >= len(name) && strings.EqualFold(rest[:len(name)], name) {
+			after := strings.TrimLeft(rest[len(name):], " \t")
+			if strings.HasPrefix(after, ">") {
+				return i, 2 + len(rest) - len(after) + 1
+			}
+		}
+		offset = i + 2
+	}
+}
+
+// externalURL returns url starting s if it has a known scheme
+func externalURL(s string) string {
+	if !urlSchemeRe.MatchString(s) {
+		return ""
+	}
+
+	end := strings.IndexAny(s, " \t\n]<>\"[")
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end]
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var tokenNames = map[TokenType]string{
+	TextToken: "text", NewlineToken: "nl", PipeToken: "|", EqualsToken: "=", LinkOpenToken: "[[",
+	LinkCloseToken: "]]", TemplateOpenToken: "{{", TemplateCloseToken: "}}", ExternalLinkOpenToken: "[url",
+	ExternalLinkCloseToken: "]", HeadingOpenToken: "h", HeadingCloseToken: "/h", TableOpenToken: "{|",
+	TableCloseToken: "|}", TagOpenToken: "<>", TagCloseToken: "</>", TagSelfClosingToken: "</>",
+	RawTagToken: "raw", CommentToken: "comment",
+}
+
+var tokenizerCorpus = []struct {
+	text   string
+	tokens string
+}{
+	{"[[a|b]]", "[[ text | text ]]"},
+	{"{{a|b=c}}", "{{ text | text = text }}"},
+	{"[http://example.org x] [y]", "[url:http://example.org text ] text ]"},
+	{"== a ==\nb", "h2 text /h nl text"},
+	{"a == b ==", "text = = text = ="},
+	{"{|\n| a\n|}", "{| nl | text nl |}"},
+	{"a {| b", "text | text"},
+	{"<div class=\"x\">a</div>", "<>div text </>div"},
+	{"<br> <ref name=a />", "</>br text </>ref"},
+	{"<nowiki>[[a]]</nowiki>", "raw:[[a]]"},
+	{"<!-- [[a]] -->b", "comment: [[a]]  text"},
+	{"a < b <foo>", "text"},
+}
+
+// describe lists token types of text, with heading level, url, tag name or raw content
+func describe(text string) string {
+	var tokens []string
+	t := NewTokenizer(text)
+	for {
+		tok, ok := t.Next()
+		if !ok {
+			break
+		}
+
+		s := tokenNames[tok.Type]
+		switch tok.Type {
+		case HeadingOpenToken:
+			s += fmt.Sprint(tok.Level)
+		case ExternalLinkOpenToken:
+			s += ":" + tok.Target
+		case TagOpenToken, TagCloseToken, TagSelfClosingToken:
+			s += tok.Name
+		case RawTagToken, CommentToken:
+			s += ":" + tok.Content
+		}
+
+		// contiguous text tokens are merged
+		if s == "text" && len(tokens) > 0 && tokens[len(tokens)-1] == "text" {
+			continue
+		}
+		tokens = append(tokens, s)
+	}
+	return strings.Join(tokens, " ")
+}
+
+func TestTokenizer(t *testing.T) {
+	for _, c := range tokenizerCorpus {
+		if got := describe(c.text); got != c.tokens {
+			t.Errorf("tokens of %q = %s, want %s", c.text, got, c.tokens)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import "strings"
+
+// TrailingSections lists, per language, titles of sections closing articles. Links found from the first of them
+// are navigation or sources rather than article body.
+var TrailingSections = map[string][]string{
+	"en": {"See also", "References", "External links", "Notes", "Bibliography", "Further reading", "Sources", "Notes and references", "Citations", "Footnotes"},
+	"fr": {"Voir aussi", "Notes et références", "Références", "Notes", "Liens externes", "Bibliographie", "Articles connexes", "Annexes", "Sources"},
+	"de": {"Siehe auch", "Literatur", "Weblinks", "Einzelnachweise", "Anmerkungen", "Quellen", "Belege"},
+	"es": {"Véase también", "Referencias", "Enlaces externos", "Notas", "Bibliografía"},
+	"it": {"Voci correlate", "Note", "Bibliografia", "Collegamenti esterni", "Altri progetti"},
+	"pt": {"Ver também", "Referências", "Ligações externas", "Notas", "Bibliografia"},
+	"nl": {"Zie ook", "Referenties", "Externe links", "Bronnen", "Noten"},
+}
+
+// TrailingSectionTitles returns trailing section titles of language, english ones if language is unknown
+func TrailingSectionTitles(language string) []string {
+	titles, ok := TrailingSections[language]
+	if !ok {
+		return TrailingSections["en"]
+	}
+	return titles
+}
+
+// IsTrailingSection returns true if heading n title is one of titles, ignoring case and spacing
+func IsTrailingSection(n *Node, titles []string, ns *Namespaces) bool {
+	if n.Type != HeadingNode {
+		return false
+	}
+
+	title := normalizeHeading(HeadingTitle(n, ns))
+	for _, t := range titles {
+		if title == normalizeHeading(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrailingStart returns index of first top level heading of nodes matching titles, len(nodes) if none does
+func TrailingStart(nodes []Node, titles []string, ns *Namespaces) int {
+	for i := range nodes {
+		if IsTrailingSection(&nodes[i], titles, ns) {
+			return i
+		}
+	}
+	return len(nodes)
+}
+
+func normalizeHeading(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
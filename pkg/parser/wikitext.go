@@ -0,0 +1,498 @@
+package parser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type NodeType int
+
+const (
+	TextNode         NodeType = iota
+	LinkNode                  // [[Target|param|...]]
+	ExternalLinkNode          // [http://target label]
+	TemplateNode              // {{Name|param|name=value}}
+	HeadingNode               // == Children ==
+	TableNode                 // {| Children |}
+	TagNode                   // <Name Attrs>Children</Name>, Text holds content of raw tags such as nowiki
+	CommentNode               // <!-- Text -->
+)
+
+// Node is an element of wikitext syntax tree. Raw is its source, starting at byte offset Pos.
+type Node struct {
+	Type     NodeType
+	Pos      int
+	Raw      string
+	Text     string
+	Name     string
+	Target   string
+	Attrs    string
+	Level    int
+	Params   []Param
+	Children []Node
+}
+
+// Param is a template parameter or a link segment. Positional parameters are named after their position, starting at "1".
+type Param struct {
+	Name  string
+	Named bool
+	Value []Node
+}
+
+// Param returns value of template parameter name, nil if not set
+func (n *Node) Param(name string) []Node {
+	for _, p := range n.Params {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return nil
+}
+
+// Label returns displayed part of a link: last segment after target, or nil if link has no label
+func (n *Node) Label() []Node {
+	if len(n.Params) == 0 {
+		return nil
+	}
+	return n.Params[len(n.Params)-1].Value
+}
+
+// Parse builds syntax tree of wikitext. Parsing never fails: unclosed or unexpected markup is kept as text.
+func Parse(text string) []Node {
+	p := &wikitextParser{
+		src:       text,
+		stops:     make(map[stop]int),
+		parsed:    make(map[attempt]parsed),
+		failed:    make(map[attempt]bool),
+		exhausted: make(map[int]bool),
+	}
+
+	t := NewTokenizer(text)
+	for {
+		tok, ok := t.Next()
+		if !ok {
+			break
+		}
+		p.tokens = append(p.tokens, tok)
+	}
+
+	// page itself is closed by its end
+	nodes, _ := p.parse(frame{autoClose: true})
+	return nodes
+}
+
+// Walk calls fn on every node and its descendants, depth first. Descendants are skipped if fn returns false.
+func Walk(nodes []Node, fn func(n *Node) bool) {
+	for i := range nodes {
+		n := &nodes[i]
+		if !fn(n) {
+			continue
+		}
+		for _, p := range n.Params {
+			Walk(p.Value, fn)
+		}
+		Walk(n.Children, fn)
+	}
+}
+
+// Text concatenates text of nodes, comments and markup excluded. Text of nested nodes is included.
+func Text(nodes []Node) string {
+	var b strings.Builder
+	Walk(nodes, func(n *Node) bool {
+		switch n.Type {
+		case TextNode:
+			b.WriteString(n.Text)
+		case CommentNode:
+			return false
+		}
+		return true
+	})
+	return b.String()
+}
+
+// frame describes the construct being parsed: tokens stopping it, and whether reaching
+// end of source or an enclosing construct delimiter closes it (true) or makes it invalid (false)
+type frame struct {
+	stops       []TokenType
+	tag         string
+	failNewline bool
+	autoClose   bool
+}
+
+func (f *frame) stopsAt(tok *Token) bool {
+	for _, s := range f.stops {
+		if tok.Type == s && (s != TagCloseToken || tok.Name == f.tag) {
+			return true
+		}
+	}
+	return false
+}
+
+type wikitextParser struct {
+	src    string
+	tokens []Token
+	pos    int
+
+	// delimiters of enclosing frames, counting frames using them, which stop nested constructs. signature lists
+	// them, as parsing a construct only depends on its position and enclosing delimiters.
+	stops     map[stop]int
+	signature string
+
+	// constructs already parsed, so they are not parsed again after an enclosing construct rewinds. Constructs
+	// reaching end of page without closing are exhausted whatever enclosing delimiters. Every enclosing construct
+	// which is not auto closed is then doomed to fail as well: frames unwind up to the outermost one, without
+	// recording auto closed constructs parsed in between. closing counts frames which are not auto closed.
+	parsed    map[attempt]parsed
+	failed    map[attempt]bool
+	exhausted map[int]bool
+	doomed    bool
+	closing   int
+}
+
+// stop is a delimiter token type, with tag name for closing tags
+type stop struct {
+	typ TokenType
+	tag string
+}
+
+// attempt is a construct opened at token index pos, within enclosing delimiters signature
+type attempt struct {
+	pos       int
+	signature string
+}
+
+// parsed is a construct successfully parsed, end being the index of the token following it
+type parsed struct {
+	node Node
+	end  int
+}
+
+// push adds delimiters of f to enclosing delimiters. '=' only splits template parameter at its own level.
+func (p *wikitextParser) push(f *frame) {
+	changed := false
+	for _, s := range f.stops {
+		if s == EqualsToken {
+			continue
+		}
+		k := stop{typ: s}
+		if s == TagCloseToken {
+			k.tag = f.tag
+		}
+		p.stops[k]++
+		changed = changed || p.stops[k] == 1
+	}
+	if changed {
+		p.sign()
+	}
+}
+
+// pop removes delimiters of f from enclosing delimiters
+func (p *wikitextParser) pop(f *frame) {
+	changed := false
+	for _, s := range f.stops {
+		if s == EqualsToken {
+			continue
+		}
+		k := stop{typ: s}
+		if s == TagCloseToken {
+			k.tag = f.tag
+		}
+		p.stops[k]--
+		if p.stops[k] == 0 {
+			delete(p.stops, k)
+			changed = true
+		}
+	}
+	if changed {
+		p.sign()
+	}
+}
+
+// sign computes signature of enclosing delimiters
+func (p *wikitextParser) sign() {
+	var stops []string
+	for k := range p.stops {
+		stops = append(stops, strconv.Itoa(int(k.typ))+"/"+k.tag)
+	}
+	sort.Strings(stops)
+	p.signature = strings.Join(stops, " ")
+}
+
+// parse returns nodes up to a stop token of f, which is not consumed. ok is false if construct is invalid.
+func (p *wikitextParser) parse(f frame) ([]Node, bool) {
+	var nodes []Node
+
+	p.push(&f)
+	defer p.pop(&f)
+	if !f.autoClose {
+		p.closing++
+		defer func() { p.closing-- }()
+	}
+
+	for p.pos < len(p.tokens) {
+		tok := &p.tokens[p.pos]
+
+		if f.stopsAt(tok) {
+			return nodes, true
+		}
+		if tok.Type == NewlineToken && f.failNewline {
+			return nodes, false
+		}
+		if p.enclosingStop(tok) {
+			return nodes, f.autoClose
+		}
+
+		p.pos++
+		switch tok.Type {
+		case LinkOpenToken:
+			nodes = p.link(nodes, tok)
+		case TemplateOpenToken:
+			nodes = p.template(nodes, tok)
+		case ExternalLinkOpenToken:
+			nodes = p.externalLink(nodes, tok)
+		case HeadingOpenToken:
+			nodes = p.heading(nodes, tok)
+		case TableOpenToken:
+			nodes = p.table(nodes, tok)
+		case TagOpenToken:
+			nodes = p.tag(nodes, tok)
+		case TagSelfClosingToken:
+			nodes = append(nodes, Node{Type: TagNode, Pos: tok.Pos, Raw: tok.Raw, Name: tok.Name, Attrs: tok.Attrs})
+		case RawTagToken:
+			nodes = append(nodes, Node{Type: TagNode, Pos: tok.Pos, Raw: tok.Raw, Name: tok.Name, Attrs: tok.Attrs, Text: tok.Content})
+		case CommentToken:
+			nodes = append(nodes, Node{Type: CommentNode, Pos: tok.Pos, Raw: tok.Raw, Text: tok.Content})
+		case TagCloseToken:
+			// closing tag without opening tag is dropped
+		default:
+			nodes = p.appendText(nodes, tok.Pos, tok.Raw)
+		}
+
+		if p.doomed {
+			if p.closing > 0 {
+				return nodes, false
+			}
+			p.doomed = false
+		}
+	}
+
+	return nodes, f.autoClose
+}
+
+// enclosingStop returns true if tok closes one of the enclosing constructs. Delimiters of the current frame are
+// checked first by stopsAt.
+func (p *wikitextParser) enclosingStop(tok *Token) bool {
+	k := stop{typ: tok.Type}
+	if tok.Type == TagCloseToken {
+		k.tag = tok.Name
+	}
+	return p.stops[k] > 0
+}
+
+// memoized appends construct opened by open if it was already parsed within same enclosing delimiters, or open
+// as text if it failed. ok is false if construct must be parsed.
+func (p *wikitextParser) memoized(nodes []Node, open *Token, a attempt) ([]Node, bool) {
+	if r, ok := p.parsed[a]; ok {
+		p.pos = r.end
+		return append(nodes, r.node), true
+	}
+	if p.failed[a] || p.exhausted[a.pos] {
+		return p.appendText(nodes, open.Pos, open.Raw), true
+	}
+	return nodes, false
+}
+
+// succeed appends parsed construct n and records it
+func (p *wikitextParser) succeed(nodes []Node, n Node, a attempt) []Node {
+	p.parsed[a] = parsed{node: n, end: p.pos}
+	return append(nodes, n)
+}
+
+// fallback rewinds to token following opening token, which is kept as text, and records failure
+func (p *wikitextParser) fallback(nodes []Node, open *Token, a attempt) []Node {
+	if p.pos >= len(p.tokens) {
+		p.doomed = true
+	}
+	if p.doomed {
+		p.exhausted[a.pos] = true
+	} else {
+		p.failed[a] = true
+	}
+
+	p.pos = a.pos
+	return p.appendText(nodes, open.Pos, open.Raw)
+}
+
+func (p *wikitextParser) link(nodes []Node, open *Token) []Node {
+	a := attempt{pos: p.pos, signature: p.signature}
+	if nodes, ok := p.memoized(nodes, open, a); ok {
+		return nodes
+	}
+
+	target, ok := p.parse(frame{stops: []TokenType{PipeToken, LinkCloseToken}, failNewline: true})
+	if !ok || p.pos >= len(p.tokens) {
+		return p.fallback(nodes, open, a)
+	}
+
+	n := Node{Type: LinkNode, Pos: open.Pos, Target: strings.TrimSpace(Text(target))}
+	for p.tokens[p.pos].Type == PipeToken {
+		p.pos++
+		value, ok := p.parse(frame{stops: []TokenType{PipeToken, LinkCloseToken}})
+		if !ok || p.pos >= len(p.tokens) {
+			return p.fallback(nodes, open, a)
+		}
+		n.Params = append(n.Params, Param{Name: strconv.Itoa(len(n.Params) + 1), Value: value})
+	}
+
+	p.pos++
+	n.Raw = p.src[n.Pos : p.tokens[p.pos-1].Pos+len(p.tokens[p.pos-1].Raw)]
+	return p.succeed(nodes, n, a)
+}
+
+func (p *wikitextParser) template(nodes []Node, open *Token) []Node {
+	a := attempt{pos: p.pos, signature: p.signature}
+	if nodes, ok := p.memoized(nodes, open, a); ok {
+		return nodes
+	}
+
+	name, ok := p.parse(frame{stops: []TokenType{PipeToken, TemplateCloseToken}})
+	if !ok || p.pos >= len(p.tokens) {
+		return p.fallback(nodes, open, a)
+	}
+
+	n := Node{Type: TemplateNode, Pos: open.Pos, Name: strings.TrimSpace(Text(name))}
+	var positional int
+	for p.tokens[p.pos].Type == PipeToken {
+		p.pos++
+
+		value, ok := p.parse(frame{stops: []TokenType{PipeToken, TemplateCloseToken, EqualsToken}})
+		if !ok || p.pos >= len(p.tokens) {
+			return p.fallback(nodes, open, a)
+		}
+
+		if p.tokens[p.pos].Type == EqualsToken {
+			p.pos++
+			key := strings.TrimSpace(Text(value))
+			value, ok = p.parse(frame{stops: []TokenType{PipeToken, TemplateCloseToken}})
+			if !ok || p.pos >= len(p.tokens) {
+				return p.fallback(nodes, open, a)
+			}
+			n.Params = append(n.Params, Param{Name: key, Named: true, Value: value})
+			continue
+		}
+
+		positional++
+		n.Params = append(n.Params, Param{Name: strconv.Itoa(positional), Value: value})
+	}
+
+	p.pos++
+	n.Raw = p.src[n.Pos : p.tokens[p.pos-1].Pos+len(p.tokens[p.pos-1].Raw)]
+	return p.succeed(nodes, n, a)
+}
+
+func (p *wikitextParser) externalLink(nodes []Node, open *Token) []Node {
+	a := attempt{pos: p.pos, signature: p.signature}
+	if nodes, ok := p.memoized(nodes, open, a); ok {
+		return nodes
+	}
+
+	label, ok := p.parse(frame{stops: []TokenType{ExternalLinkCloseToken}, failNewline: true})
+	if !ok || p.pos >= len(p.tokens) {
+		return p.fallback(nodes, open, a)
+	}
+	p.pos++
+
+	// label is separated from url by spaces
+	if len(label) > 0 && label[0].Type == TextNode {
+		label[0].Text = strings.TrimLeft(label[0].Text, " \t")
+		if label[0].Text == "" {
+			label = label[1:]
+		}
+	}
+
+	n := Node{Type: ExternalLinkNode, Pos: open.Pos, Target: open.Target, Children: label}
+	n.Raw = p.src[n.Pos : p.tokens[p.pos-1].Pos+len(p.tokens[p.pos-1].Raw)]
+	return p.succeed(nodes, n, a)
+}
+
+func (p *wikitextParser) heading(nodes []Node, open *Token) []Node {
+	a := attempt{pos: p.pos, signature: p.signature}
+	if nodes, ok := p.memoized(nodes, open, a); ok {
+		return nodes
+	}
+
+	title, _ := p.parse(frame{stops: []TokenType{HeadingCloseToken}, autoClose: true})
+	if p.doomed {
+		return nodes
+	}
+
+	n := Node{Type: HeadingNode, Pos: open.Pos, Level: open.Level, Children: title}
+	end := len(p.src)
+	if p.pos < len(p.tokens) && p.tokens[p.pos].Type == HeadingCloseToken {
+		end = p.tokens[p.pos].Pos + len(p.tokens[p.pos].Raw)
+		p.pos++
+	} else if p.pos < len(p.tokens) {
+		end = p.tokens[p.pos].Pos
+	}
+
+	n.Raw = p.src[n.Pos:end]
+	return p.succeed(nodes, n, a)
+}
+
+func (p *wikitextParser) table(nodes []Node, open *Token) []Node {
+	a := attempt{pos: p.pos, signature: p.signature}
+	if nodes, ok := p.memoized(nodes, open, a); ok {
+		return nodes
+	}
+
+	f := frame{stops: []TokenType{TableCloseToken}, autoClose: true}
+	content, _ := p.parse(f)
+	if p.doomed {
+		return nodes
+	}
+	return p.succeed(nodes, p.closeBlock(Node{Type: TableNode, Pos: open.Pos, Children: content}, &f), a)
+}
+
+func (p *wikitextParser) tag(nodes []Node, open *Token) []Node {
+	a := attempt{pos: p.pos, signature: p.signature}
+	if nodes, ok := p.memoized(nodes, open, a); ok {
+		return nodes
+	}
+
+	f := frame{stops: []TokenType{TagCloseToken}, tag: open.Name, autoClose: true}
+	content, _ := p.parse(f)
+	if p.doomed {
+		return nodes
+	}
+	return p.succeed(nodes, p.closeBlock(Node{Type: TagNode, Pos: open.Pos, Name: open.Name, Attrs: open.Attrs, Children: content}, &f), a)
+}
+
+// closeBlock consumes closing token of auto closed constructs if present and sets node Raw
+func (p *wikitextParser) closeBlock(n Node, f *frame) Node {
+	end := len(p.src)
+	if p.pos < len(p.tokens) {
+		end = p.tokens[p.pos].Pos
+		if f.stopsAt(&p.tokens[p.pos]) {
+			end += len(p.tokens[p.pos].Raw)
+			p.pos++
+		}
+	}
+
+	n.Raw = p.src[n.Pos:end]
+	return n
+}
+
+// appendText appends raw to last node if it is contiguous text, creating a new text node otherwise
+func (p *wikitextParser) appendText(nodes []Node, pos int, raw string) []Node {
+	if len(nodes) > 0 {
+		last := &nodes[len(nodes)-1]
+		if last.Type == TextNode && last.Pos+len(last.Raw) == pos {
+			last.Raw = p.src[last.Pos : pos+len(raw)]
+			last.Text = last.Raw
+			return nodes
+		}
+	}
+
+	return append(nodes, Node{Type: TextNode, Pos: pos, Raw: raw, Text: raw})
+}
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// shape renders syntax tree compactly: L(target|param) for links, T(name|param|key=value) for templates,
+// X(url|label) for external links, H2(title) for headings, TABLE(content), <tag>(content), <tag>"text" for raw
+// tags and C"text" for comments
+func shape(nodes []Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n.Type {
+		case TextNode:
+			b.WriteString(n.Text)
+		case LinkNode:
+			b.WriteString("L(" + n.Target)
+			for _, p := range n.Params {
+				b.WriteString("|" + shape(p.Value))
+			}
+			b.WriteString(")")
+		case TemplateNode:
+			b.WriteString("T(" + n.Name)
+			for _, p := range n.Params {
+				b.WriteString("|")
+				if p.Named {
+					b.WriteString(p.Name + "=")
+				}
+				b.WriteString(shape(p.Value))
+			}
+			b.WriteString(")")
+		case ExternalLinkNode:
+			b.WriteString("X(" + n.Target + "|" + shape(n.Children) + ")")
+		case HeadingNode:
+			fmt.Fprintf(&b, "H%d(%s)", n.Level, shape(n.Children))
+		case TableNode:
+			b.WriteString("TABLE(" + shape(n.Children) + ")")
+		case TagNode:
+			if rawTags[n.Name] {
+				fmt.Fprintf(&b, "<%s>%q", n.Name, n.Text)
+				continue
+			}
+			fmt.Fprintf(&b, "<%s>(%s)", n.Name, shape(n.Children))
+		case CommentNode:
+			fmt.Fprintf(&b, "C%q", n.Text)
+		}
+	}
+	return b.String()
+}
+
+var wikitextCorpus = []struct {
+	name  string
+	text  string
+	shape string
+}{
+	{"link", "[[Paris]]", "L(Paris)"},
+	{"link label", "[[Paris|the capital]] city", "L(Paris|the capital) city"},
+	{"link trail", "a [[b]]c", "a L(b)c"},
+	{"image caption links", "[[File:Eiffel.jpg|thumb|The [[Eiffel Tower]] in [[Paris|the capital]]]]",
+		"L(File:Eiffel.jpg|thumb|The L(Eiffel Tower) in L(Paris|the capital))"},
+	{"template in link", "[[Paris|{{lang|fr|Paris}}]]", "L(Paris|T(lang|fr|Paris))"},
+	{"link in template", "{{main|[[France]]|see=[[Paris]]}}", "T(main|L(France)|see=L(Paris))"},
+	{"nested templates", "{{a|{{b|c}}|d={{e}}}}", "T(a|T(b|c)|d=T(e))"},
+	{"template equals in value", "{{a|b=c=d}}", "T(a|b=c=d)"},
+	{"template multiline", "{{Infobox\n| name = Paris\n| area = 105\n}}", "T(Infobox|name= Paris\n|area= 105\n)"},
+	{"external link", "[http://example.org Example site]", "X(http://example.org|Example site)"},
+	{"external link without label", "[https://example.org]", "X(https://example.org|)"},
+	{"bracket without url", "[not a link]", "[not a link]"},
+	{"nowiki", "<nowiki>[[Paris]] {{b}}</nowiki>", `<nowiki>"[[Paris]] {{b}}"`},
+	{"nowiki in template", "{{a|<nowiki>}}</nowiki>}}", `T(a|<nowiki>"}}")`},
+	{"comment", "a<!-- [[Hidden]] -->b", `aC" [[Hidden]] "b`},
+	{"comment in template", "{{a|<!-- }} -->b}}", `T(a|C" }} "b)`},
+	{"unclosed comment", "a<!-- b", `aC" b"`},
+	{"heading", "== History ==\ntext", "H2( History )\ntext"},
+	{"heading with link", "=== [[Paris]] ===", "H3( L(Paris) )"},
+	{"table", "{|\n| a || [[b]]\n|}", "TABLE(\n| a || L(b)\n)"},
+	{"tag", "<div>[[a]]</div>", "<div>(L(a))"},
+	{"self closing ref", `a<ref name="x" />`, "a<ref>()"},
+	{"ref with citation", "<ref>{{cite web|url=http://a.org}}</ref>", "<ref>(T(cite web|url=http://a.org))"},
+	{"unclosed link", "[[Paris and [[Lyon]]", "[[Paris and L(Lyon)"},
+	{"unclosed template", "{{a|b [[c]]", "{{a|b L(c)"},
+	{"link across lines", "[[a\nb]]", "[[a\nb]]"},
+	{"link closed by template end", "{{a|[[b}}]]", "T(a|[[b)]]"},
+	{"stray closers", "a]] b}} c]", "a]] b}} c]"},
+	{"unclosed tag", "<div>a {{b}}", "<div>(a T(b))"},
+	{"closing tag without opening", "a</div>b", "ab"},
+	{"unknown tag", "a <foo> b", "a <foo> b"},
+}
+
+func TestParse(t *testing.T) {
+	for _, c := range wikitextCorpus {
+		nodes := Parse(c.text)
+		if got := shape(nodes); got != c.shape {
+			t.Errorf("%s: Parse(%q) = %s, want %s", c.name, c.text, got, c.shape)
+		}
+
+		// every node source is found at its offset
+		Walk(nodes, func(n *Node) bool {
+			if c.text[n.Pos:n.Pos+len(n.Raw)] != n.Raw {
+				t.Errorf("%s: node %q not found at offset %d", c.name, n.Raw, n.Pos)
+			}
+			return true
+		})
+	}
+}
+
+func TestParseUnclosedNesting(t *testing.T) {
+	// each unclosed level used to double parsing time
+	for _, s := range []string{"{{", "[[a|{{b|", "<div>{{a|", "{{a|[[b|[http://x.org y ", "== {{a|\n"} {
+		Walk(Parse(strings.Repeat(s, 2000)), func(n *Node) bool {
+			if n.Type == LinkNode || n.Type == TemplateNode || n.Type == ExternalLinkNode {
+				t.Errorf("Parse(%q x 2000) returned unclosed %q", s, n.Raw)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+var referenceCorpus = []struct {
+	name string
+	text string
+	refs []string
+}{
+	// first character of targets used to be dropped
+	{"first character", "[[Paris]] and [[A]]", []string{"A", "Paris"}},
+	{"image caption", "[[File:Eiffel.jpg|thumb|The [[Eiffel Tower]]]]", []string{"Eiffel Tower"}},
+	{"template in label", "[[Paris|{{lang|fr|Paris}}]]", []string{"Paris"}},
+	{"link in template", "{{main|[[France]]}}", []string{"France"}},
+	{"nowiki", "<nowiki>[[Paris]]</nowiki>", nil},
+	{"comment", "<!-- [[Paris]] -->", nil},
+	{"unclosed", "[[Paris [[Lyon]]", []string{"Lyon"}},
+	{"colon in title", "[[Star Wars: Episode IV]]", []string{"Star Wars: Episode IV"}},
+	{"anchor", "[[France#History|history]]", []string{"France"}},
+	{"lowercase first letter", "[[paris]]", []string{"Paris"}},
+	{"non article links", "[[Category:Capitals]] [[fr:Paris]] [[wikt:paris]] [[Template:Coord]]", nil},
+}
+
+func TestReferences(t *testing.T) {
+	for _, c := range referenceCorpus {
+		var titles []string
+		for title := range References(Parse(c.text), nil, DefaultNamespaces) {
+			titles = append(titles, title)
+		}
+		sort.Strings(titles)
+
+		if strings.Join(titles, ", ") != strings.Join(c.refs, ", ") {
+			t.Errorf("%s: References(%q) = %v, want %v", c.name, c.text, titles, c.refs)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// Category is a subcategory found walking a category tree. Depth is 1 for direct subcategories of root.
+// A category reachable through several paths is returned once per parent, at its lowest depth.
+type Category struct {
+	Title  string
+	Parent string
+	Depth  int
+}
+
+// Member is a page of a category tree
+type Member struct {
+	PageID   int
+	Title    string
+	Category string
+	SortKey  string
+}
+
+// CategoryTree returns subcategories of category in wiki, walking subcategory edges recursively down to depth.
+// category is normalized as a title, so 'capitals_in_Europe' finds 'Capitals in Europe'. Category cycles are cut by
+// depth limit.
+func CategoryTree(db *sql.DB, wiki string, category string, depth int) ([]Category, error) {
+	query := `WITH RECURSIVE tree (lower_title, title, lower_parent, depth) AS (
+			SELECT lower_title, title, lower_parent, 1 FROM subcategory WHERE wiki = $1 AND lower_parent = $2
+			UNION ALL
+			SELECT s.lower_title, s.title, s.lower_parent, t.depth + 1
+			FROM subcategory s JOIN tree t ON s.wiki = $1 AND s.lower_parent = t.lower_title
+			WHERE t.depth < $3
+		)
+		SELECT t.title, COALESCE(p.title, t.lower_parent), min(t.depth)
+		FROM tree t
+		LEFT JOIN (SELECT DISTINCT lower_title, title FROM subcategory WHERE wiki = $1) p ON p.lower_title = t.lower_parent
+		GROUP BY t.lower_title, t.title, t.lower_parent, p.title
+		ORDER BY 3, 1`
+
+	root := title.Normalize(category, title.FirstLetter)
+	rows, err := db.Query(query, wiki, title.Key(root), depth)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%s: category tree : %s", wiki, category, err)
+	}
+	defer rows.Close()
+
+	var categories []Category
+	for rows.Next() {
+		var c Category
+		err = rows.Scan(&c.Title, &c.Parent, &c.Depth)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(c.Parent, root) {
+			c.Parent = root
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// CategoryMembers returns pages of category and of its subcategories down to depth. category is normalized as a title.
+func CategoryMembers(db *sql.DB, wiki string, category string, depth int) ([]Member, error) {
+	categories := []string{title.Key(title.Normalize(category, title.FirstLetter))}
+	if depth > 0 {
+		tree, err := CategoryTree(db, wiki, category, depth)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range tree {
+			categories = append(categories, title.Key(c.Title))
+		}
+	}
+
+	var members []Member
+	seen := make(map[string]bool)
+	for _, key := range categories {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		query := `SELECT p.page_id, p.title, c.category, COALESCE(c.sort_key, '')
+			FROM page_category c
+			JOIN page p ON p.wiki = c.wiki AND p.page_id = c.page_id
+			WHERE c.wiki = $1 AND c.lower_category = $2
+			ORDER BY 4, 2`
+		rows, err := db.Query(query, wiki, key)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%s: category members : %s", wiki, key, err)
+		}
+
+		for rows.Next() {
+			var m Member
+			err = rows.Scan(&m.PageID, &m.Title, &m.Category, &m.SortKey)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			members = append(members, m)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return members, nil
+}
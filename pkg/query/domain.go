@@ -0,0 +1,62 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DomainCount counts links to a domain. Pages is the number of pages linking to it.
+type DomainCount struct {
+	Wiki   string
+	Domain string
+	Links  int
+	Pages  int
+}
+
+// TopDomains returns the limit most linked domains of wiki, only counting links found in citations if cited is set
+func TopDomains(db *sql.DB, wiki string, cited bool, limit int) ([]DomainCount, error) {
+	query := `SELECT domain, count(*), count(DISTINCT page_id) FROM external_link
+		WHERE wiki = $1 AND domain != '' AND (in_citation OR NOT $2)
+		GROUP BY domain
+		ORDER BY 2 DESC, 1
+		LIMIT $3`
+
+	rows, err := db.Query(query, wiki, cited, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: top domains : %s", wiki, err)
+	}
+	defer rows.Close()
+
+	var domains []DomainCount
+	for rows.Next() {
+		d := DomainCount{Wiki: wiki}
+		err = rows.Scan(&d.Domain, &d.Links, &d.Pages)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+
+	return domains, rows.Err()
+}
+
+// LinkedWikis returns wikis having external links imported
+func LinkedWikis(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT DISTINCT wiki FROM external_link ORDER BY wiki`)
+	if err != nil {
+		return nil, fmt.Errorf("linked wikis : %s", err)
+	}
+	defer rows.Close()
+
+	var wikis []string
+	for rows.Next() {
+		var wiki string
+		err = rows.Scan(&wiki)
+		if err != nil {
+			return nil, err
+		}
+		wikis = append(wikis, wiki)
+	}
+
+	return wikis, rows.Err()
+}
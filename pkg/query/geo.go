@@ -0,0 +1,76 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/geo"
+)
+
+// Place is a page with its primary coordinate. Distance is in kilometers, set by WithinRadius only.
+type Place struct {
+	PageID   int
+	Title    string
+	Lat      float64
+	Lon      float64
+	Distance float64
+}
+
+// WithinBox returns pages of wiki whose primary coordinate is inside bounding box b, using geohash index
+func WithinBox(db *sql.DB, wiki string, b geo.Box) ([]Place, error) {
+	query := `SELECT g.page_id, p.title, g.lat, g.lon
+		FROM page_geo g JOIN page p ON p.wiki = g.wiki AND p.page_id = g.page_id
+		WHERE g.wiki = $1 AND g.geohash >= $2 AND g.geohash < $3 AND g.is_primary`
+
+	var places []Place
+	for _, prefix := range geo.Cover(b) {
+		// '~' sorts after every geohash character
+		rows, err := db.Query(query, wiki, prefix, prefix+"~")
+		if err != nil {
+			return nil, fmt.Errorf("%s: page_geo %s : %s", wiki, prefix, err)
+		}
+
+		for rows.Next() {
+			var p Place
+			err = rows.Scan(&p.PageID, &p.Title, &p.Lat, &p.Lon)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if b.Contains(p.Lat, p.Lon) {
+				places = append(places, p)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return places, nil
+}
+
+// WithinRadius returns pages of wiki whose primary coordinate is less than radius kilometers away from lat, lon,
+// closest first
+func WithinRadius(db *sql.DB, wiki string, lat, lon, radius float64) ([]Place, error) {
+	candidates, err := WithinBox(db, wiki, geo.RadiusBox(lat, lon, radius))
+	if err != nil {
+		return nil, err
+	}
+
+	var places []Place
+	for _, p := range candidates {
+		p.Distance = geo.Distance(lat, lon, p.Lat, p.Lon)
+		if p.Distance <= radius {
+			places = append(places, p)
+		}
+	}
+
+	sort.Slice(places, func(i, j int) bool {
+		return places[i].Distance < places[j].Distance
+	})
+
+	return places, nil
+}
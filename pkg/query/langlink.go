@@ -0,0 +1,81 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Version is a language version of a page
+type Version struct {
+	Wiki   string
+	PageID int
+	Title  string
+}
+
+// LanguageVersions returns all language versions of page titled title in wiki, itself included.
+//
+// Versions come from interlanguage links of the page and from links of other wikis pointing to it,
+// so a version is found even if only one side declares the link. PageID is 0 for versions whose wiki isn't imported.
+func LanguageVersions(db *sql.DB, wiki string, title string) ([]Version, error) {
+	page := Version{Wiki: wiki}
+	var err error
+	page.PageID, page.Title, err = FindPage(db, wiki, title)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[string]Version{wiki: page}
+
+	query := `SELECT l.target_wiki, COALESCE(l.target_page_id, 0), COALESCE(p.title, l.target_title)
+		FROM page_langlink l
+		LEFT JOIN page p ON p.wiki = l.target_wiki AND p.page_id = l.target_page_id
+		WHERE l.wiki = $1 AND l.page_id = $2`
+	err = scanVersions(db, versions, query, wiki, page.PageID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT l.wiki, l.page_id, p.title
+		FROM page_langlink l
+		JOIN page p ON p.wiki = l.wiki AND p.page_id = l.page_id
+		WHERE l.target_wiki = $1 AND l.target_page_id = $2`
+	err = scanVersions(db, versions, query, wiki, page.PageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Version
+	for _, v := range versions {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Wiki < list[j].Wiki
+	})
+
+	return list, nil
+}
+
+// scanVersions adds versions returned by query, an imported version (PageID set) replacing an unresolved one
+func scanVersions(db *sql.DB, versions map[string]Version, query string, args ...interface{}) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("SELECT page_langlink : %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v := Version{}
+		err = rows.Scan(&v.Wiki, &v.PageID, &v.Title)
+		if err != nil {
+			return err
+		}
+
+		existing, ok := versions[v.Wiki]
+		if !ok || (existing.PageID == 0 && v.PageID != 0) {
+			versions[v.Wiki] = v
+		}
+	}
+
+	return rows.Err()
+}
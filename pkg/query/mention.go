@@ -0,0 +1,85 @@
+package query
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Mention counts how often a surface form links to a page
+type Mention struct {
+	Surface string
+	PageID  int
+	Title   string
+	Count   int
+}
+
+// Mentions calls fn with mentions of each page of wiki, most frequent surface forms first. References are streamed
+// ordered by target page, so only mentions of one page are held in memory. Only references imported with
+// --with-reference-mentions are counted.
+func Mentions(db *sql.DB, wiki string, fn func([]Mention) error) error {
+	query := `SELECT r.refered_page, p.title, r.surface_forms
+		FROM article_reference r JOIN page p ON p.wiki = r.wiki AND p.page_id = r.refered_page
+		WHERE r.wiki = $1 AND r.surface_forms IS NOT NULL
+		ORDER BY r.refered_page`
+
+	rows, err := db.Query(query, wiki)
+	if err != nil {
+		return fmt.Errorf("%s: mentions : %s", wiki, err)
+	}
+	defer rows.Close()
+
+	var current int
+	var title string
+	counts := make(map[string]int)
+	flush := func() error {
+		if len(counts) == 0 {
+			return nil
+		}
+		mentions := make([]Mention, 0, len(counts))
+		for surface, count := range counts {
+			mentions = append(mentions, Mention{Surface: surface, PageID: current, Title: title, Count: count})
+		}
+		sort.Slice(mentions, func(i, j int) bool {
+			if mentions[i].Count != mentions[j].Count {
+				return mentions[i].Count > mentions[j].Count
+			}
+			return mentions[i].Surface < mentions[j].Surface
+		})
+		counts = make(map[string]int)
+		return fn(mentions)
+	}
+
+	for rows.Next() {
+		var id int
+		var t, surfaces string
+		err = rows.Scan(&id, &t, &surfaces)
+		if err != nil {
+			return err
+		}
+
+		if id != current {
+			err = flush()
+			if err != nil {
+				return err
+			}
+			current, title = id, t
+		}
+
+		var m map[string]int
+		err = json.Unmarshal([]byte(surfaces), &m)
+		if err != nil {
+			return fmt.Errorf("%s: surface forms of %s : %s", wiki, t, err)
+		}
+		for surface, count := range m {
+			counts[surface] += count
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
+)
+
+// Collision is a set of distinct pages whose titles only differ by case, such as 'MIT' and 'Mit'
+type Collision struct {
+	Key    string
+	Titles []string
+}
+
+// FindPage returns id and title of page titled t in wiki. t is normalized with wiki first letter case, and looked up
+// exactly first. If no page matches, pages matching case insensitively are looked up, returning an error if several
+// pages collide.
+func FindPage(db *sql.DB, wiki string, t string) (int, string, error) {
+	var id int
+	normalized := title.Normalize(t, title.FirstLetter)
+
+	query := `SELECT page_id FROM page WHERE wiki = $1 AND title = $2`
+	err := db.QueryRow(query, wiki, normalized).Scan(&id)
+	if err == nil {
+		return id, normalized, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, "", fmt.Errorf("%s:%s: %s", wiki, t, err)
+	}
+
+	query = `SELECT page_id, title FROM page WHERE wiki = $1 AND lower_title = $2 LIMIT 10`
+	rows, err := db.Query(query, wiki, title.Key(normalized))
+	if err != nil {
+		return 0, "", fmt.Errorf("%s:%s: %s", wiki, t, err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		err = rows.Scan(&id, &normalized)
+		if err != nil {
+			return 0, "", err
+		}
+		titles = append(titles, normalized)
+	}
+	err = rows.Err()
+	if err != nil {
+		return 0, "", err
+	}
+
+	switch len(titles) {
+	case 0:
+		return 0, "", fmt.Errorf("%s:%s: %s", wiki, t, sql.ErrNoRows)
+	case 1:
+		return id, normalized, nil
+	}
+	return 0, "", fmt.Errorf("%s:%s: ambiguous title, matches %s", wiki, t, strings.Join(titles, ", "))
+}
+
+// TitleCollisions returns titles of wiki sharing the same lower_title, so case insensitive lookups of them are
+// ambiguous
+func TitleCollisions(db *sql.DB, wiki string) ([]Collision, error) {
+	query := `SELECT p.lower_title, p.title FROM page p
+		JOIN (SELECT lower_title FROM page WHERE wiki = $1 GROUP BY lower_title HAVING count(*) > 1) c
+		ON c.lower_title = p.lower_title
+		WHERE p.wiki = $1
+		ORDER BY p.lower_title, p.title`
+
+	rows, err := db.Query(query, wiki)
+	if err != nil {
+		return nil, fmt.Errorf("%s: title collisions : %s", wiki, err)
+	}
+	defer rows.Close()
+
+	var collisions []Collision
+	for rows.Next() {
+		var key, t string
+		err = rows.Scan(&key, &t)
+		if err != nil {
+			return nil, err
+		}
+		if len(collisions) == 0 || collisions[len(collisions)-1].Key != key {
+			collisions = append(collisions, Collision{Key: key})
+		}
+		last := &collisions[len(collisions)-1]
+		last.Titles = append(last.Titles, t)
+	}
+
+	return collisions, rows.Err()
+}
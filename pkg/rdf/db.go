@@ -0,0 +1,69 @@
+package rdf
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+)
+
+// FromDB writes every page and article reference of wiki stored in database
+func FromDB(db *sql.DB, wiki string, w *Writer) error {
+	query := `SELECT p.title, COALESCE(n.nature, 0) FROM page p LEFT JOIN page_nature n ON n.wiki = p.wiki AND n.page_id = p.page_id WHERE p.wiki = $1`
+	rows, err := db.Query(query, wiki)
+	if err != nil {
+		return fmt.Errorf("SELECT page : %s", err)
+	}
+
+	var title string
+	var nature parser.Nature
+	var pages int
+	for rows.Next() {
+		err = rows.Scan(&title, &nature)
+		if err == nil {
+			err = w.WritePage(title, nature)
+		}
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pages++
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("SELECT page : %s", err)
+	}
+	fmt.Printf("Wrote %d pages\n", pages)
+
+	query = `SELECT r.page_id, s.title, r.refered_page, t.title, r.occurrence, r.reference_index
+		FROM article_reference r
+		JOIN page s ON s.wiki = r.wiki AND s.page_id = r.page_id
+		JOIN page t ON t.wiki = r.wiki AND t.page_id = r.refered_page
+		WHERE r.wiki = $1`
+	rows, err = db.Query(query, wiki)
+	if err != nil {
+		return fmt.Errorf("SELECT article_reference : %s", err)
+	}
+	defer rows.Close()
+
+	var pageID, referedID, occurrence, index, references int
+	var referedTitle string
+	for rows.Next() {
+		err = rows.Scan(&pageID, &title, &referedID, &referedTitle, &occurrence, &index)
+		if err == nil {
+			err = w.WriteReference(pageID, title, referedID, referedTitle, occurrence, index)
+		}
+		if err != nil {
+			return err
+		}
+		references++
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("SELECT article_reference : %s", err)
+	}
+	fmt.Printf("Wrote %d references\n", references)
+
+	return nil
+}
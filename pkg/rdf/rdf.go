@@ -0,0 +1,163 @@
+package rdf
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/parser"
+)
+
+const (
+	// Vocabulary is the namespace of classes and predicates specific to this tool
+	Vocabulary = "https://github.com/proullon/wikipedia-to-cockroachdb/vocabulary#"
+
+	rdfNS  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	rdfsNS = "http://www.w3.org/2000/01/rdf-schema#"
+	xsdNS  = "http://www.w3.org/2001/XMLSchema#"
+)
+
+// Writer streams triples as gzip compressed N-Triples ('nt') or Turtle ('ttl')
+type Writer struct {
+	language string
+	turtle   bool
+
+	gz *gzip.Writer
+	w  *bufio.Writer
+}
+
+func NewWriter(out io.Writer, format string, language string) (*Writer, error) {
+	if format != "nt" && format != "ttl" {
+		return nil, fmt.Errorf("unknown rdf format '%s'", format)
+	}
+
+	gz := gzip.NewWriter(out)
+	w := &Writer{
+		language: language,
+		turtle:   format == "ttl",
+		gz:       gz,
+		w:        bufio.NewWriter(gz),
+	}
+
+	if w.turtle {
+		_, err := fmt.Fprintf(w.w, "@prefix rdf: <%s> .\n@prefix rdfs: <%s> .\n@prefix xsd: <%s> .\n@prefix wtc: <%s> .\n\n", rdfNS, rdfsNS, xsdNS, Vocabulary)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// IRI returns the stable IRI of a page, which is its canonical Wikipedia URL
+func (w *Writer) IRI(title string) string {
+	return fmt.Sprintf("<https://%s.wikipedia.org/wiki/%s>", w.language, escapeTitle(title))
+}
+
+// WritePage writes page type, label and nature, unknown nature being omitted.
+// Nature is a class of the vocabulary, such as wtc:Human.
+func (w *Writer) WritePage(title string, nature parser.Nature) error {
+	s := w.IRI(title)
+
+	err := w.triple(s, w.term(rdfNS, "rdf", "type"), w.term(Vocabulary, "wtc", "Page"))
+	if err != nil {
+		return err
+	}
+
+	err = w.triple(s, w.term(rdfsNS, "rdfs", "label"), fmt.Sprintf("%s@%s", literal(title), w.language))
+	if err != nil {
+		return err
+	}
+
+	if nature != parser.UnknownNature {
+		name := nature.String()
+		err = w.triple(s, w.term(rdfNS, "rdf", "type"), w.term(Vocabulary, "wtc", strings.ToUpper(name[:1])+name[1:]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteReference writes a link from page to refered page. Occurrence and index annotate
+// the link through a reified statement, identified by both page ids.
+func (w *Writer) WriteReference(pageID int, title string, referedID int, referedTitle string, occurrence int, index int) error {
+	s := w.IRI(title)
+	p := w.term(Vocabulary, "wtc", "refersTo")
+	o := w.IRI(referedTitle)
+	r := fmt.Sprintf("_:ref%d_%d", pageID, referedID)
+
+	triples := [][3]string{
+		{s, p, o},
+		{r, w.term(rdfNS, "rdf", "type"), w.term(rdfNS, "rdf", "Statement")},
+		{r, w.term(rdfNS, "rdf", "subject"), s},
+		{r, w.term(rdfNS, "rdf", "predicate"), p},
+		{r, w.term(rdfNS, "rdf", "object"), o},
+		{r, w.term(Vocabulary, "wtc", "occurrence"), w.integer(occurrence)},
+		{r, w.term(Vocabulary, "wtc", "referenceIndex"), w.integer(index)},
+	}
+
+	for _, t := range triples {
+		err := w.triple(t[0], t[1], t[2])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flushes buffered triples and gzip stream, it doesn't close underlying writer
+func (w *Writer) Close() error {
+	err := w.w.Flush()
+	if err != nil {
+		return err
+	}
+
+	return w.gz.Close()
+}
+
+func (w *Writer) triple(s, p, o string) error {
+	_, err := fmt.Fprintf(w.w, "%s %s %s .\n", s, p, o)
+	return err
+}
+
+// term returns prefixed name in Turtle and full IRI in N-Triples
+func (w *Writer) term(ns string, prefix string, name string) string {
+	if w.turtle {
+		return prefix + ":" + name
+	}
+	return "<" + ns + name + ">"
+}
+
+func (w *Writer) integer(i int) string {
+	return fmt.Sprintf(`"%d"^^%s`, i, w.term(xsdNS, "xsd", "integer"))
+}
+
+func literal(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// escapeTitle builds URL path of a title the way MediaWiki does: spaces become
+// underscores and characters outside of the safe set are percent encoded
+func escapeTitle(title string) string {
+	const safe = "!$&'()*+,-./:;=@_~"
+
+	title = strings.Replace(title, " ", "_", -1)
+
+	var b strings.Builder
+	for i := 0; i < len(title); i++ {
+		c := title[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte(safe, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
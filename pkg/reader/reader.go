@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/proullon/wikipedia-to-cockroachdb/pkg/title"
 )
 
 type Dump struct {
@@ -17,14 +19,26 @@ type Dump struct {
 }
 
 type SiteInfo struct {
-	SiteName string `xml:"sitename"`
-	DBName   string `xml:"dbname"`
+	SiteName   string      `xml:"sitename"`
+	DBName     string      `xml:"dbname"`
+	Case       string      `xml:"case"`
+	Namespaces []Namespace `xml:"namespaces>namespace"`
+}
+
+// Namespace is a wiki namespace, such as 'Category' with key 14. Main namespace has key 0 and no name.
+type Namespace struct {
+	Key  int    `xml:"key,attr"`
+	Case string `xml:"case,attr"`
+	Name string `xml:",chardata"`
 }
 
+// Page is a dump page. Ns is its namespace key, 0 for articles.
 type Page struct {
-	Title string `xml:"title"`
-	ID    int    `xml:"id"`
-	Text  string `xml:"revision>text"`
+	Title     string `xml:"title"`
+	Ns        int    `xml:"ns"`
+	ID        int    `xml:"id"`
+	Text      string `xml:"revision>text"`
+	Timestamp string `xml:"revision>timestamp"`
 }
 
 type Reader struct {
@@ -86,6 +100,11 @@ func ReadDump(filename string) (*Dump, error) {
 		return nil, err
 	}
 
+	c := title.ParseCase(d.Info.Case)
+	for i := range d.Pages {
+		d.Pages[i].Title = title.Normalize(d.Pages[i].Title, c)
+	}
+
 	return d, nil
 }
 
@@ -111,6 +130,7 @@ func StreamDumpPages(filename string) (*SiteInfo, chan Page, error) {
 	}
 
 	pchan := make(chan Page, 10)
+	c := title.ParseCase(si.Case)
 
 	go func() {
 		defer close(pchan)
@@ -124,6 +144,7 @@ func StreamDumpPages(filename string) (*SiteInfo, chan Page, error) {
 				log.Infof("Done reading %s: %s", filename, err)
 				return
 			}
+			p.Title = title.Normalize(p.Title, c)
 
 			pchan <- p
 		}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open creates or opens the SQLite database file. Schema is created by migration package.
+//
+// SQLite only allows one writer at a time, so the pool is kept to 2 connections:
+// one for the insert transaction and one for page lookups, possible thanks to WAL journal mode.
+func Open(filename string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)", filename)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(2)
+
+	return db, nil
+}
+
+// BuildFullTextIndex fills FTS5 index on page titles and, if withContent is set, on page content.
+func BuildFullTextIndex(db *sql.DB, withContent bool) error {
+	tables := []string{"page_title_fts"}
+	if withContent {
+		tables = append(tables, "page_content_fts")
+	}
+
+	for _, t := range tables {
+		fmt.Printf("Building full text index %s\n", t)
+		begin := time.Now()
+
+		query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES ('rebuild')`, t, t)
+		_, err := db.Exec(query)
+		if err != nil {
+			return fmt.Errorf("%s: rebuild : %s", t, err)
+		}
+
+		fmt.Printf("Built %s (took %s)\n", t, time.Since(begin))
+	}
+
+	return nil
+}
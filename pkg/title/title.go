@@ -0,0 +1,71 @@
+// Package title normalizes page titles following MediaWiki rules, so titles read from dumps, link targets found in
+// wikitext and titles given by users compare equal when MediaWiki considers them the same page.
+package title
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Case is the title case sensitivity of a wiki, declared in dump siteinfo
+type Case int
+
+const (
+	// FirstLetter wikis, such as Wikipedia, uppercase title first letter: [[paris]] links to 'Paris'
+	FirstLetter Case = iota
+	// CaseSensitive wikis, such as Wiktionary, keep titles as written
+	CaseSensitive
+)
+
+// ParseCase returns case of siteinfo case value, 'first-letter' or 'case-sensitive'. Wikis default to FirstLetter.
+func ParseCase(s string) Case {
+	if s == "case-sensitive" {
+		return CaseSensitive
+	}
+	return FirstLetter
+}
+
+// Normalize returns title s as stored by MediaWiki: HTML entities decoded, underscores and whitespace runs replaced
+// by a single space, Unicode NFC normalized and first letter uppercased if c is FirstLetter.
+// Namespace prefix, if any, is considered part of title.
+func Normalize(s string, c Case) string {
+	s = html.UnescapeString(s)
+	s = norm.NFC.String(s)
+
+	s = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+
+	if c == FirstLetter {
+		s = upperFirst(s)
+	}
+
+	return s
+}
+
+// Key returns case insensitive form of normalized title s, as stored in lower_title columns. Distinct pages, such as
+// 'MIT' and 'Mit', may share the same key, see query.TitleCollisions.
+func Key(s string) string {
+	return strings.ToLower(s)
+}
+
+// Fold returns Key of s normalized, for titles not yet normalized such as user input
+func Fold(s string) string {
+	return Key(Normalize(s, CaseSensitive))
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
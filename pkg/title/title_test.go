@@ -0,0 +1,62 @@
+package title
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		c    Case
+		want string
+	}{
+		{"already normalized", "Paris", FirstLetter, "Paris"},
+		{"first letter", "paris", FirstLetter, "Paris"},
+		{"case sensitive", "paris", CaseSensitive, "paris"},
+		{"first letter only", "iPhone", FirstLetter, "IPhone"},
+		{"non ascii first letter", "école normale", FirstLetter, "École normale"},
+		{"underscores", "Eiffel_Tower", FirstLetter, "Eiffel Tower"},
+		{"whitespace runs", "  Eiffel \t_ Tower\n", FirstLetter, "Eiffel Tower"},
+		{"named entity", "AT&amp;T", FirstLetter, "AT&T"},
+		{"numeric entity", "Caf&#233;", FirstLetter, "Café"},
+		{"non breaking space entity", "New&nbsp;York", FirstLetter, "New York"},
+		{"decomposed accent", "Cafe\u0301", FirstLetter, "Caf\u00e9"},
+		{"namespace prefix kept", "category:capitals", FirstLetter, "Category:capitals"},
+		{"uppercase kept", "MIT", FirstLetter, "MIT"},
+		{"empty", " _ ", FirstLetter, ""},
+	}
+
+	for _, c := range cases {
+		if got := Normalize(c.s, c.c); got != c.want {
+			t.Errorf("%s: Normalize(%q) = %q, want %q", c.name, c.s, got, c.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	// distinct pages share the same key
+	if Key("MIT") != Key("Mit") {
+		t.Errorf("Key(MIT) = %q, Key(Mit) = %q, want equal", Key("MIT"), Key("Mit"))
+	}
+	if got := Key("École Normale"); got != "école normale" {
+		t.Errorf("Key(École Normale) = %q, want %q", got, "école normale")
+	}
+}
+
+func TestFold(t *testing.T) {
+	cases := []struct {
+		s    string
+		want string
+	}{
+		{"MIT", "mit"},
+		{"mit", "mit"},
+		{"Eiffel_tower", "eiffel tower"},
+		{" Caf&#233;  de_Flore ", "café de flore"},
+		{"Cafe\u0301", "caf\u00e9"},
+	}
+
+	for _, c := range cases {
+		if got := Fold(c.s); got != c.want {
+			t.Errorf("Fold(%q) = %q, want %q", c.s, got, c.want)
+		}
+	}
+}